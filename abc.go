@@ -0,0 +1,217 @@
+// Package abc is the public API of the ABC metrics tool. It lets other Go
+// programs compute Assignment, Branch, Condition metrics without shelling out
+// to the abc binary:
+//
+//	result, err := abc.Analyze("main.go")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(result.Score, result.Severity)
+//
+// # Compatibility
+//
+// Exported identifiers of this package are not removed or changed in
+// incompatible ways within a major version; new functions, fields and options
+// may be added. The counting rules themselves are refined over time, so the
+// exact counts for a given source file are not part of this guarantee.
+// Packages under internal/ may change at any time.
+package abc
+
+import (
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// Metrics holds the Assignment, Branch and Condition counts of a file or
+// function together with the details of every counted item
+type Metrics = metrics.ABCMetrics
+
+// Detail is a single counted item, or a warning, with its position
+type Detail = metrics.MetricDetail
+
+// FunctionMetrics holds the metrics of a single function or method
+type FunctionMetrics = metrics.FunctionMetrics
+
+// Analyzer computes metrics for the files of one language
+type Analyzer = analyzer.Analyzer
+
+// UnsupportedFileError is returned for files no analyzer supports
+type UnsupportedFileError = analyzer.UnsupportedFileError
+
+// DirectoryError is returned when the path to analyze is a directory
+type DirectoryError = analyzer.DirectoryError
+
+// Options selects optional counting rules. The zero value gives the default
+// counting behavior of the abc command. The counting rules only apply to Go
+// files; other languages are always counted with the default rules.
+type Options struct {
+	// RangeAssignments counts the variables bound by range loops as assignments
+	RangeAssignments bool
+
+	// Guards tallies early-return guard clauses in Metrics.Guards
+	Guards bool
+
+	// LintGoroutines adds warnings for goroutines that are never joined
+	LintGoroutines bool
+
+	// Returns counts return statements as branches
+	Returns bool
+
+	// LiteralElements counts each element of a composite literal as an
+	// assignment
+	LiteralElements bool
+
+	// SkipInitAssignments leaves out assignments in the init statements of
+	// if, switch and for statements and the post statements of for loops
+	SkipInitAssignments bool
+
+	// SkipBlankAssignments leaves out assignments to the blank identifier _
+	SkipBlankAssignments bool
+
+	// SkipDeferredCalls leaves out the calls of defer statements
+	SkipDeferredCalls bool
+
+	// SkipTypeAssertions leaves out type assertions such as x.(T)
+	SkipTypeAssertions bool
+
+	// Comparisons counts every comparison operator as a condition
+	Comparisons bool
+
+	// Nesting records the nesting depth of every condition in its detail
+	// context and the deepest one in Metrics.MaxNesting
+	Nesting bool
+
+	// ByFunction fills Result.Functions for languages that support it
+	ByFunction bool
+
+	// Weights scales the terms of Result.Score. The zero value means
+	// DefaultWeights.
+	Weights Weights
+}
+
+// Result is the analysis result of a single file
+type Result struct {
+	Path      string            // Path of the analyzed file
+	Metrics   Metrics           // Counts and details of the whole file
+	Score     float64           // ABC score, sqrt(A² + B² + C²) unless weighted by Options.Weights
+	Severity  string            // Severity level of the score, see SeverityLevel
+	Functions []FunctionMetrics // Per-function metrics, only with Options.ByFunction
+}
+
+// Analyze analyzes the file at path with the default counting rules
+func Analyze(path string) (Result, error) {
+	return AnalyzeWithOptions(path, Options{})
+}
+
+// AnalyzeWithOptions analyzes the file at path with the given options
+func AnalyzeWithOptions(path string, opts Options) (Result, error) {
+	a, err := analyzer.GetAnalyzerForFileWithConfig(path, opts.countConfig())
+	if err != nil {
+		return Result{}, err
+	}
+
+	m, err := a.AnalyzeFile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	result := newResult(path, m, opts.weights())
+
+	if functionAnalyzer, ok := a.(analyzer.FunctionAnalyzer); ok && opts.ByFunction {
+		result.Functions, err = functionAnalyzer.AnalyzeFileByFunction(path)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// AnalyzeSource analyzes source code held in memory. The filename selects the
+// language by its extension and is used for position information.
+func AnalyzeSource(filename string, src []byte, opts Options) (Result, error) {
+	a, err := analyzer.GetAnalyzerForFileWithConfig(filename, opts.countConfig())
+	if err != nil {
+		return Result{}, err
+	}
+
+	m, err := a.AnalyzeSource(filename, src)
+	if err != nil {
+		return Result{}, err
+	}
+	result := newResult(filename, m, opts.weights())
+
+	if functionAnalyzer, ok := a.(analyzer.FunctionAnalyzer); ok && opts.ByFunction {
+		result.Functions, err = functionAnalyzer.AnalyzeSourceByFunction(filename, src)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// AnalyzerFor returns the analyzer for the file at path based on its
+// extension, or an *UnsupportedFileError
+func AnalyzerFor(path string) (Analyzer, error) {
+	return analyzer.GetAnalyzerForFile(path)
+}
+
+// Weights scales the assignment, branch and condition terms of the score
+type Weights = metrics.Weights
+
+// DefaultWeights weighs every term equally, giving the classic score
+var DefaultWeights = metrics.DefaultWeights
+
+// SeverityConfig holds the score cutoffs between severity levels
+type SeverityConfig = metrics.SeverityConfig
+
+// DefaultSeverityConfig holds the default cutoffs: 10, 20 and 40
+var DefaultSeverityConfig = metrics.DefaultSeverityConfig
+
+// SeverityLevel returns the severity level of an ABC score: Low, Medium, High
+// or Very High
+func SeverityLevel(score float64) string {
+	return metrics.SeverityLevel(score)
+}
+
+// SeverityLevelWith returns the severity level of an ABC score using the
+// given cutoffs
+func SeverityLevelWith(score float64, cfg SeverityConfig) string {
+	return metrics.SeverityLevelWith(score, cfg)
+}
+
+// newResult wraps file metrics in a Result, scored with the given weights
+func newResult(path string, m Metrics, w Weights) Result {
+	score := m.ScoreWith(w)
+	return Result{
+		Path:     path,
+		Metrics:  m,
+		Score:    score,
+		Severity: metrics.SeverityLevel(score),
+	}
+}
+
+// weights returns the score weights, defaulting the zero value
+func (o Options) weights() Weights {
+	if o.Weights == (Weights{}) {
+		return DefaultWeights
+	}
+	return o.Weights
+}
+
+// countConfig converts the options into the analyzer counting rules
+func (o Options) countConfig() analyzer.CountConfig {
+	return analyzer.CountConfig{
+		CountRangeAssignments: o.RangeAssignments,
+		CountGuards:           o.Guards,
+		LintGoroutines:        o.LintGoroutines,
+		CountReturns:          o.Returns,
+		CountLiteralElements:  o.LiteralElements,
+		SkipInitAssignments:   o.SkipInitAssignments,
+		SkipBlankAssignments:  o.SkipBlankAssignments,
+		SkipDeferredCalls:     o.SkipDeferredCalls,
+		SkipTypeAssertions:    o.SkipTypeAssertions,
+		CountComparisons:      o.Comparisons,
+		TrackNesting:          o.Nesting,
+	}
+}
@@ -0,0 +1,150 @@
+package abc
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// source has one function with one assignment, branch and condition and
+// one empty function
+const source = `package p
+
+func f(x int) int {
+	y := x
+	if y > 0 {
+		return g(y)
+	}
+	return y
+}
+
+func g(x int) int { return x }
+`
+
+// writeSource writes source to a Go file in a temporary directory
+func writeSource(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "p.go")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// checkResult compares the counts and score of a result
+func checkResult(t *testing.T, r Result, a, b, c int, score float64) {
+	t.Helper()
+	if r.Metrics.Assignments != a || r.Metrics.Branches != b || r.Metrics.Conditions != c {
+		t.Errorf("counts = <%d,%d,%d>, want <%d,%d,%d>",
+			r.Metrics.Assignments, r.Metrics.Branches, r.Metrics.Conditions, a, b, c)
+	}
+	if math.Abs(r.Score-score) > 1e-9 {
+		t.Errorf("Score = %v, want %v", r.Score, score)
+	}
+	if r.Severity != SeverityLevel(r.Score) {
+		t.Errorf("Severity = %q, want %q", r.Severity, SeverityLevel(r.Score))
+	}
+}
+
+// checkFunctions compares the names and counts of per-function results
+func checkFunctions(t *testing.T, functions []FunctionMetrics) {
+	t.Helper()
+	want := []struct {
+		name    string
+		a, b, c int
+	}{
+		{"f", 1, 1, 1},
+		{"g", 0, 0, 0},
+	}
+	if len(functions) != len(want) {
+		t.Fatalf("got %d functions, want %d", len(functions), len(want))
+	}
+	for i, w := range want {
+		f := functions[i]
+		if f.Name != w.name || f.Metrics.Assignments != w.a || f.Metrics.Branches != w.b || f.Metrics.Conditions != w.c {
+			t.Errorf("function %d = %s <%d,%d,%d>, want %s <%d,%d,%d>", i, f.Name,
+				f.Metrics.Assignments, f.Metrics.Branches, f.Metrics.Conditions, w.name, w.a, w.b, w.c)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	path := writeSource(t)
+
+	r, err := Analyze(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Path != path {
+		t.Errorf("Path = %q, want %q", r.Path, path)
+	}
+	checkResult(t, r, 1, 1, 1, math.Sqrt(3))
+	if r.Functions != nil {
+		t.Errorf("Functions = %v, want nil without ByFunction", r.Functions)
+	}
+}
+
+func TestAnalyzeUnsupportedFile(t *testing.T) {
+	_, err := Analyze("README.md")
+	var unsupported *UnsupportedFileError
+	if !errors.As(err, &unsupported) {
+		t.Errorf("err = %v, want *UnsupportedFileError", err)
+	}
+}
+
+func TestAnalyzeWithOptionsByFunction(t *testing.T) {
+	r, err := AnalyzeWithOptions(writeSource(t), Options{ByFunction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, r, 1, 1, 1, math.Sqrt(3))
+	checkFunctions(t, r.Functions)
+}
+
+func TestAnalyzeWithOptionsWeights(t *testing.T) {
+	r, err := AnalyzeWithOptions(writeSource(t), Options{Weights: Weights{A: 4, B: 1, C: 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, r, 1, 1, 1, math.Sqrt(5))
+}
+
+func TestAnalyzeSource(t *testing.T) {
+	r, err := AnalyzeSource("p.go", []byte(source), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Path != "p.go" {
+		t.Errorf("Path = %q, want %q", r.Path, "p.go")
+	}
+	checkResult(t, r, 1, 1, 1, math.Sqrt(3))
+	if r.Functions != nil {
+		t.Errorf("Functions = %v, want nil without ByFunction", r.Functions)
+	}
+}
+
+func TestAnalyzeSourceByFunction(t *testing.T) {
+	r, err := AnalyzeSource("p.go", []byte(source), Options{ByFunction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFunctions(t, r.Functions)
+}
+
+func TestAnalyzeSourceMatchesAnalyze(t *testing.T) {
+	path := writeSource(t)
+	fromFile, err := AnalyzeWithOptions(path, Options{ByFunction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSource, err := AnalyzeSource(path, []byte(source), Options{ByFunction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromFile.Score != fromSource.Score || len(fromFile.Functions) != len(fromSource.Functions) {
+		t.Errorf("AnalyzeSource = %v, %d functions, want %v, %d functions",
+			fromSource.Score, len(fromSource.Functions), fromFile.Score, len(fromFile.Functions))
+	}
+}
@@ -0,0 +1,619 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	saveRawPath       string
+	rangeAssignments  bool
+	countGuards       bool
+	lintGoroutines    bool
+	analyzeTimeout    time.Duration
+	strict            bool
+	jsonStream        bool
+	byFunction        bool
+	threshold         float64
+	functionThreshold float64
+	jobs              int
+	excludePatterns   []string
+	skipTests         bool
+	countReturns      bool
+	sinceRef          string
+	includeGenerated  bool
+	buildTags         []string
+	summaryOnly       bool
+	groupBy           string
+	groupDepth        int
+	topFiles          int
+	baselinePath      string
+	baselineTolerance float64
+	baselineFormat    string
+	minScore          float64
+	literalElements   bool
+	failOnSeverity    string
+	skipInit          bool
+	skipBlank         bool
+	trackNesting      bool
+	quiet             bool
+	cacheDir          string
+	noCache           bool
+	skipDefers        bool
+	skipTypeAsserts   bool
+	countComparisons  bool
+	countSpec         string
+	maxFiles          int
+	failFast          bool
+	withMeta          bool
+	allowUnsupported  bool
+)
+
+// defaultMaxFiles is the default of --max-files, far above the size of most
+// projects but low enough to stop a scan of a home directory early
+const defaultMaxFiles = 50000
+
+func init() {
+	addAnalysisFlags(analyzeCmd)
+	analyzeCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print only the score of each file, one per line, and only errors on stderr")
+	analyzeCmd.Flags().BoolVar(&summaryOnly, "summary", false, "Print only the combined metrics and the highest-scoring files instead of per-file results")
+	analyzeCmd.Flags().StringVar(&groupBy, "group-by", "", "Print the combined metrics per directory instead of per-file results: dir (text and json only)")
+	analyzeCmd.Flags().IntVar(&groupDepth, "group-depth", 0, "With --group-by dir, roll directories up to their first N path elements (0 keeps the full directory)")
+	analyzeCmd.Flags().IntVar(&topFiles, "top", 10, "Number of highest-scoring files listed by --summary (0 disables the list)")
+	analyzeCmd.Flags().Float64Var(&minScore, "min-score", 0, "Only print files scoring at least this value; totals, --threshold and --baseline still cover every file")
+	analyzeCmd.Flags().StringVar(&sinceRef, "since", "", "Only count items on lines changed since this git ref, e.g. origin/main")
+	analyzeCmd.Flags().Float64Var(&threshold, "threshold", 0, "Exit with code 2 if a file, function or the combined total scores above this value (0 disables)")
+	analyzeCmd.Flags().Float64Var(&functionThreshold, "function-threshold", 0, "Exit with code 2 if a function scores above this value (0 disables, implies --by-function)")
+	analyzeCmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", "Exit with code 2 if a file is rated at this severity or above (low, medium, high, very-high)")
+	analyzeCmd.Flags().BoolVar(&byFunction, "by-function", false, "Break metrics down per function, sorted by descending score")
+	analyzeCmd.Flags().BoolVar(&lintGoroutines, "lint-goroutines", false, "Warn about functions starting goroutines that are never joined")
+	analyzeCmd.Flags().BoolVar(&withMeta, "with-meta", false, "Wrap json output in an object with a meta block describing the run: tool and analyzer versions, timestamp, paths and the flags set (default true when more than one file may be analyzed, unless --json-always-array is set)")
+	analyzeCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop at the first file failing --threshold, --function-threshold, --fail-on-severity or --baseline and exit with code 2")
+	analyzeCmd.Flags().BoolVar(&strict, "strict", false, "Treat recoverable problems such as timeouts and syntax errors as errors")
+	analyzeCmd.Flags().BoolVar(&jsonStream, "json-stream", false, "Serve analysis requests as JSON lines on stdin, answering on stdout")
+	analyzeCmd.Flags().StringVar(&baselinePath, "baseline", "", "Exit with code 2 if a file scores above its score in this baseline file written by 'baseline'")
+	analyzeCmd.Flags().Float64Var(&baselineTolerance, "baseline-tolerance", 0, "How much a file's score may grow over its baseline before --baseline fails")
+	analyzeCmd.Flags().StringVar(&baselineFormat, "baseline-format", report.BaselineFormatABC, "Format of the --baseline file: abc (written by 'baseline') or simple (a flat {\"path\": score} object)")
+	analyzeCmd.Flags().StringVar(&saveRawPath, "save-raw", "", "Save the full analysis results to a raw JSON file for later rendering with 'report'")
+	analyzeCmd.MarkFlagFilename("baseline", "json")
+	analyzeCmd.MarkFlagFilename("save-raw", "json")
+	completeValues(analyzeCmd, "group-by", "dir")
+	completeValues(analyzeCmd, "baseline-format", report.BaselineFormats...)
+	completeValues(analyzeCmd, "fail-on-severity", "low", "medium", "high", "very-high")
+}
+
+// addAnalysisFlags registers the flags that select and count files, shared by
+// every command that analyzes source files
+func addAnalysisFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&excludePatterns, "exclude", nil, "Skip paths matching this gitignore-style pattern while walking directories (repeatable)")
+	cmd.Flags().BoolVar(&includeGenerated, "include-generated", false, "Analyze generated Go files (with a \"Code generated ... DO NOT EDIT.\" header) found while walking directories")
+	cmd.Flags().StringSliceVar(&buildTags, "tags", nil, "Build tags satisfied while walking directories; Go files excluded by build constraints for these tags, GOOS and GOARCH are skipped")
+	cmd.Flags().BoolVar(&skipTests, "skip-tests", false, "Skip vendor directories and *_test.go files while walking directories")
+	cmd.Flags().BoolVar(&allowUnsupported, "allow-unsupported", false, "Skip files no analyzer supports with a warning instead of failing on them (default true when more than one file may be analyzed)")
+	cmd.Flags().IntVar(&maxFiles, "max-files", defaultMaxFiles, "Fail without analyzing anything if the paths expand to more than this many files (0 disables the limit)")
+	addCountFlags(cmd)
+}
+
+// addCountFlags registers the flags that control how files are analyzed and
+// counted, for commands that do not walk directories themselves
+func addCountFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Number of files to analyze concurrently")
+	cmd.Flags().BoolVar(&rangeAssignments, "range-assignments", false, "Count variables bound by range loops as assignments")
+	cmd.Flags().BoolVar(&literalElements, "literal-elements", false, "Count each element of map, slice and struct literals as an assignment")
+	cmd.Flags().BoolVar(&skipInit, "skip-init-assignments", false, "Do not count assignments in the init statements of if, switch and for statements and the post statements of for loops")
+	cmd.Flags().BoolVar(&skipBlank, "skip-blank", false, "Do not count assignments to the blank identifier _")
+	cmd.Flags().BoolVar(&countReturns, "count-returns", false, "Count return statements as branches")
+	cmd.Flags().BoolVar(&skipDefers, "skip-defers", false, "Do not count the calls of defer statements as branches")
+	cmd.Flags().BoolVar(&skipTypeAsserts, "skip-type-assertions", false, "Do not count type assertions such as x.(T) as conditions")
+	cmd.Flags().BoolVar(&countComparisons, "count-comparisons", false, "Count comparison operators (==, !=, <, <=, >, >=) as conditions")
+	cmd.Flags().StringVar(&countSpec, "spec", analyzer.DefaultSpec, "Counting convention for Go files, other languages always use the default: "+strings.Join(analyzer.SpecNames(), ", ")+"; the counting flags enable rules on top of it")
+	cmd.Flags().BoolVar(&trackNesting, "nesting", false, "Record the nesting depth of every condition and report the deepest one")
+	cmd.Flags().BoolVar(&countGuards, "count-guards", false, "Report how many conditions are early-return guard clauses")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory of the result cache (default abc in the user cache directory)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Analyze every file instead of reusing cached results of unchanged files")
+	cmd.MarkFlagDirname("cache-dir")
+	completeValues(cmd, "spec", analyzer.SpecNames()...)
+	cmd.Flags().DurationVar(&analyzeTimeout, "timeout", 0, "Maximum time to spend analyzing a file, e.g. 10s (0 means no limit)")
+}
+
+// analyzeCmd represents the analyze command
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [path...]",
+	Short: "Analyze files or directories for ABC metrics",
+	Long: `Analyze one or more files and calculate their ABC metrics.
+
+Arguments can be any mix of files, directories and glob patterns, and each
+is resolved on its own: an existing directory is walked, any other existing
+path is analyzed as a file, and a path that does not exist is expanded as a
+glob pattern when it contains *, ? or [. A file reached through several
+arguments is analyzed once. When more than one file may be analyzed, named
+files that no analyzer supports are skipped with a warning; a single
+unsupported file is an error. --allow-unsupported sets either behavior
+explicitly.
+
+When a path is a directory, the tree is walked recursively and every file
+with a supported extension is analyzed. Unsupported files found while
+walking are skipped and symbolic links to directories are not followed.
+Paths matching --exclude patterns, the config file's ignore list or a
+.abcignore file in the walked directory are skipped as well, and so are
+generated Go files unless --include-generated is set. Go files excluded by
+their build constraints or _GOOS/_GOARCH file name suffix are skipped too,
+evaluated for the GOOS and GOARCH environment variables (the current
+platform by default) and the build tags given by --tags. If the paths
+expand to more than --max-files files (50000 by default), the walk stops
+and the command fails before analyzing anything.
+
+Files are analyzed concurrently (see --jobs) and reported in path order.
+With the text and jsonl formats, each file is printed as soon as it and the
+files before it are done, so output starts before the whole scan finishes.
+When more than one file is analyzed, a combined total is printed last.
+JSON output for more than one file is an object with a "meta" block
+describing the run and a "total" object next to the "files" array, and CSV
+output ends with a TOTAL row; --with-meta=false and --include-total=false
+leave them out, --json-always-array keeps the bare array, and --with-meta
+and --include-total add them for a single file as well.
+With --summary, per-file results are left out of the text output and only
+the combined metrics and the --top highest-scoring files are printed.
+With --min-score, files scoring below the given value are left out of the
+output, including the --summary list, but still count towards the total.
+With --since, only assignments, branches and conditions on lines changed
+since the given git ref are counted, so scores reflect the change itself.
+Files with syntax errors are analyzed as far as they parse and reported
+with a warning per error. Files that cannot be analyzed are reported and
+skipped; when several files are analyzed, they are listed again in an
+"N files failed" section on stderr at the end. JSON output then becomes an
+object holding the files and a "failures" array of {"path", "error"},
+except with --json-always-array, which keeps the array of the files that
+succeeded. JSON Lines output gets a {"path", "error"} line in place of the
+file. The
+command exits with code 1 if any file failed, which takes precedence over
+the exit code 2 of the checks below; those are still reported.
+With --baseline, files whose score grew past the score recorded by the
+'baseline' command by more than --baseline-tolerance, and files missing
+from the baseline, are reported and the command exits with code 2.
+--baseline-format simple reads a baseline from other tools instead, a flat
+JSON object of scores per path.
+With --quiet, only the score of each file is printed, one number per line
+in path order, for use in scripts; exit codes are unaffected.
+With --group-by dir, files are grouped by directory and one line with the
+combined metrics of each directory is printed instead of per-file results;
+--group-depth N rolls deeper directories up into their ancestor at depth N,
+for example to match team or module boundaries. Directories scoring below
+--min-score are left out. Only the text and json formats are supported.
+With --fail-on-severity, files rated at the given severity or above are
+reported and the command exits with code 2 as well; it can be combined with
+--threshold and --baseline, and any of them failing fails the command.
+With --function-threshold, every function scoring above the given value is
+listed as path:line name (score), pointing at the line the function starts
+on, and the command exits with code 2; it implies --by-function.
+By default every file is analyzed and every breach reported. With
+--fail-fast, the scan stops at the first file failing one of these checks:
+the output is completed with the files analyzed so far, the breach is
+reported and the command exits with code 2.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if jsonStream {
+			if err := runJSONStream(os.Stdin, os.Stdout); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := validateFormat(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if quiet && format != formatText {
+			logErrorf("--quiet only applies to the text format, got %q", format)
+			os.Exit(1)
+		}
+		if err := validateGroupBy(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		if functionThreshold > 0 {
+			byFunction = true
+		}
+
+		// Collect the paths to analyze from --file and the arguments
+		paths := args
+		if filePath != "" {
+			paths = append([]string{filePath}, args...)
+		}
+		if len(paths) == 0 {
+			logErrorf("file path is required")
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		// Directories are walked recursively, anything else is a single file
+		files, multiple, failed, err := expandPaths(paths)
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		warnGoOnlyRules(files)
+		jsonMeta = runMeta(cmd, paths, multiple)
+		includeTotal = runTotal(cmd, multiple)
+
+		var minSeverity string
+		if failOnSeverity != "" {
+			var err error
+			if minSeverity, err = metrics.ParseSeverityLevel(failOnSeverity); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+		}
+
+		var baseline report.Baseline
+		if baselinePath != "" {
+			var err error
+			if baseline, err = report.ReadBaseline(baselinePath, baselineFormat); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+		}
+
+		if jobs < 1 {
+			logErrorf("--jobs must be at least 1, got %d", jobs)
+			os.Exit(1)
+		}
+
+		closeOutput, err := openOutput()
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		// With --fail-fast, the first file failing a check ends the scan
+		var breached func(report.FileResult) bool
+		stoppedEarly := false
+		if failFast {
+			breached = func(result report.FileResult) bool {
+				single := []report.FileResult{result}
+				stoppedEarly = threshold > 0 && len(thresholdBreaches(single, nil, threshold)) > 0 ||
+					functionThreshold > 0 && len(functionBreaches(single, functionThreshold)) > 0 ||
+					minSeverity != "" && len(severityBreaches(single, minSeverity)) > 0 ||
+					baselinePath != "" && len(baseline.Regressions(single, baselineTolerance, scoreWeights())) > 0
+				return stoppedEarly
+			}
+		}
+
+		results, total, failures, err := runAnalysis(files, multiple, breached)
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		failed = failed || len(failures) > 0
+
+		if err := closeOutput(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		// Save raw results if requested
+		if saveRawPath != "" {
+			if err := report.WriteRaw(saveRawPath, results); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+		}
+
+		if multiple && len(failures) > 0 {
+			printFailures(failures)
+		}
+
+		exceeded := false
+		if threshold > 0 {
+			// The total of a scan cut short by --fail-fast covers only part
+			// of the files
+			if stoppedEarly {
+				total = nil
+			}
+			if breaches := thresholdBreaches(results, total, threshold); len(breaches) > 0 {
+				printBreaches(breaches, threshold)
+				exceeded = true
+			}
+		}
+		if functionThreshold > 0 {
+			if breaches := functionBreaches(results, functionThreshold); len(breaches) > 0 {
+				printFunctionBreaches(breaches, functionThreshold)
+				exceeded = true
+			}
+		}
+		if minSeverity != "" {
+			if breaches := severityBreaches(results, minSeverity); len(breaches) > 0 {
+				printSeverityBreaches(breaches, minSeverity)
+				exceeded = true
+			}
+		}
+		if baselinePath != "" {
+			if regressions := baseline.Regressions(results, baselineTolerance, scoreWeights()); len(regressions) > 0 {
+				printRegressions(regressions, baselinePath)
+				exceeded = true
+			}
+		}
+
+		// Only fail once every file has had its chance; failures take
+		// precedence over the checks above
+		if failed {
+			os.Exit(1)
+		}
+		if exceeded {
+			os.Exit(exitThreshold)
+		}
+	},
+}
+
+// runAnalysis analyzes the files and prints the results in the selected
+// format, followed by the summary or the combined total. Files scoring below
+// --min-score are analyzed but not printed, and with --quiet only the score
+// of each printed file is. Text and JSON Lines output is written as soon as
+// each file is done, the other formats once all files are. It returns the
+// results, the combined total when multiple is set, and the files that
+// failed; failures are reported on stderr as they come up, listed again at
+// the end when several files were analyzed, and included in JSON and JSON
+// Lines output. When breached is non-nil and reports a result as failing a
+// check, the remaining files are left out and the output is completed with
+// the files analyzed so far. The returned error is only set when the
+// results could not be written.
+func runAnalysis(files []string, multiple bool, breached func(report.FileResult) bool) ([]report.FileResult, *metrics.ABCMetrics, []report.Failure, error) {
+	// Per-file text output is replaced by the summary with --summary
+	perFile := format == formatText && !summaryOnly && !quiet && groupBy == ""
+
+	var results, shown []report.FileResult
+	var failures []report.Failure
+	stoppedAt := ""
+
+	// Stopping early must not leave the pool analyzing the remaining files
+	done := make(chan struct{})
+	defer close(done)
+	for outcome := range analyzeFiles(files, jobs, done) {
+		path, result, err := outcome.path, outcome.result, outcome.err
+
+		// A skipped file gets no header, only the warning
+		var timeoutErr *analyzer.TimeoutError
+		if errors.As(err, &timeoutErr) && !strict {
+			logWarnf("analysis of %s exceeded %s, skipping", path, analyzeTimeout)
+			continue
+		}
+
+		if err == nil && result.Metrics.Partial && strict {
+			err = errors.New("file has syntax errors")
+			printWarnings(path, result.Metrics)
+		}
+		if err != nil {
+			logErrorf("analyzing %s: %v", path, err)
+			failure := report.Failure{Path: displayPath(path), Error: err.Error()}
+			failures = append(failures, failure)
+			if format == formatJSONL {
+				if err := report.WriteJSONLFailure(stdout, failure); err != nil {
+					return nil, nil, failures, err
+				}
+			}
+			continue
+		}
+		results = append(results, result)
+
+		// Failed files are only listed on stderr, without a header
+		hidden := score(result.Metrics) < minScore
+		if perFile && !hidden {
+			if len(shown) > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "Analyzing file: %s\n", displayPath(path))
+		}
+
+		stop := breached != nil && breached(result)
+		if stop {
+			stoppedAt = path
+		}
+		if hidden {
+			if stop {
+				break
+			}
+			continue
+		}
+		shown = append(shown, result)
+
+		switch {
+		case quiet:
+			fmt.Fprintf(stdout, "%.2f\n", score(result.Metrics))
+		case perFile:
+			printMetrics(result.Path, result.Metrics)
+			if len(result.Functions) > 0 {
+				printFunctions(result.Functions)
+			}
+		case groupBy != "":
+			// Printed per directory below
+		case format == formatJSONL:
+			if err := report.WriteJSONLine(stdout, result, jsonOptions(), scoreWeights(), severityConfig()); err != nil {
+				return nil, nil, failures, err
+			}
+		}
+		printWarnings(path, result.Metrics)
+		if stop {
+			break
+		}
+	}
+	if stoppedAt != "" {
+		if skipped := len(files) - len(results) - len(failures); skipped > 0 {
+			logInfof("Stopped at %s (--fail-fast), %d files not analyzed", displayPath(stoppedAt), skipped)
+		}
+	}
+
+	// JSON Lines are written as files complete, only the total is left
+	if groupBy != "" {
+		if err := printDirectories(report.GroupByDirectory(results, groupDepth)); err != nil {
+			return nil, nil, failures, err
+		}
+	} else if format == formatJSONL {
+		if total := structuredTotal(results); total != nil {
+			if err := report.WriteJSONLTotal(stdout, *total, jsonOptions(), scoreWeights(), severityConfig()); err != nil {
+				return nil, nil, failures, err
+			}
+		}
+	} else if err := printResults(shown, multiple, structuredTotal(results), failures); err != nil {
+		return nil, nil, failures, err
+	}
+
+	// Combine the results when more than one file was requested
+	var total *metrics.ABCMetrics
+	if multiple {
+		all := make([]metrics.ABCMetrics, 0, len(results))
+		for _, result := range results {
+			all = append(all, result.Metrics)
+		}
+		combined := metrics.CombineMetrics(all...)
+		total = &combined
+	}
+
+	if quiet {
+		// Nothing but the scores
+	} else if summaryOnly && format == formatText {
+		all := make([]metrics.ABCMetrics, 0, len(results))
+		for _, result := range results {
+			all = append(all, result.Metrics)
+		}
+		printSummary(results, metrics.CombineMetrics(all...), topFiles)
+	} else if total != nil && format == formatText && len(results) > 1 {
+		printTotal(len(results), *total)
+	}
+
+	return results, total, failures, nil
+}
+
+// analyzePath analyzes a single file, including the per-function breakdown
+// when requested, reusing the cached result when the file is unchanged
+func analyzePath(path string) (report.FileResult, error) {
+	result, err := analyzeCached(path)
+	if err != nil {
+		return report.FileResult{}, err
+	}
+	// A cached result keeps the path it was stored under, which may be
+	// spelled differently, e.g. ./main.go for main.go
+	result.Path = path
+
+	// Keep only what the change since the given ref touched
+	if sinceRef != "" {
+		if result, err = restrictToChanges(result, sinceRef); err != nil {
+			return report.FileResult{}, err
+		}
+	}
+
+	result.Path = displayPath(path)
+	return result, nil
+}
+
+// analyzeFile runs the analyzer for a single file, including the
+// per-function breakdown when requested
+func analyzeFile(path string) (report.FileResult, error) {
+	// Get analyzer for file
+	fileAnalyzer, err := analyzer.GetAnalyzerForFileWithConfig(path, countConfig())
+	if err != nil {
+		return report.FileResult{}, err
+	}
+
+	content, err := analyzer.ReadSource(path)
+	if err != nil {
+		return report.FileResult{}, err
+	}
+	return analyzeSource(fileAnalyzer, path, content)
+}
+
+// analyzeSource runs the analyzer for the content of a file already read,
+// including the per-function breakdown when requested, so the file is read
+// only once
+func analyzeSource(fileAnalyzer analyzer.Analyzer, path string, content []byte) (report.FileResult, error) {
+	logDebugf("analyzing %s", path)
+
+	// Analyze file
+	start := time.Now()
+	abcMetrics, err := analyzer.AnalyzeSourceWithTimeout(fileAnalyzer, path, content, analyzeTimeout)
+	if err != nil {
+		return report.FileResult{}, err
+	}
+
+	result := report.FileResult{Path: path, Metrics: abcMetrics}
+
+	// Break down per function when the analyzer supports it, within what is
+	// left of --timeout for the file
+	if functionAnalyzer, ok := fileAnalyzer.(analyzer.FunctionAnalyzer); ok && byFunction {
+		timeout := time.Duration(0)
+		if analyzeTimeout > 0 {
+			timeout = analyzeTimeout - time.Since(start)
+			if timeout <= 0 {
+				return report.FileResult{}, &analyzer.TimeoutError{FilePath: path}
+			}
+		}
+		result.Functions, err = analyzer.AnalyzeSourceByFunctionWithTimeout(functionAnalyzer, path, content, timeout)
+		if err != nil {
+			return report.FileResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// countConfig builds the analyzer counting rules from --spec and the
+// command-line flags, which enable rules on top of the spec. An unknown spec
+// is rejected by validateSpec before any file is analyzed.
+func countConfig() analyzer.CountConfig {
+	spec, _ := analyzer.SpecConfig(countSpec)
+	return analyzer.CountConfig{
+		CountRangeAssignments: spec.CountRangeAssignments || rangeAssignments,
+		CountGuards:           spec.CountGuards || countGuards,
+		LintGoroutines:        spec.LintGoroutines || lintGoroutines,
+		CountReturns:          spec.CountReturns || countReturns,
+		CountLiteralElements:  spec.CountLiteralElements || literalElements,
+		SkipInitAssignments:   spec.SkipInitAssignments || skipInit,
+		SkipBlankAssignments:  spec.SkipBlankAssignments || skipBlank,
+		SkipDeferredCalls:     spec.SkipDeferredCalls || skipDefers,
+		SkipTypeAssertions:    spec.SkipTypeAssertions || skipTypeAsserts,
+		CountComparisons:      spec.CountComparisons || countComparisons,
+		TrackNesting:          spec.TrackNesting || trackNesting,
+	}
+}
+
+// warnGoOnlyRules warns when counting rules, from --spec or the counting
+// flags, are in effect but some of the files are not Go files, which the
+// other analyzers count with the default rules regardless
+func warnGoOnlyRules(files []string) {
+	if countConfig() == (analyzer.CountConfig{}) {
+		return
+	}
+	other := 0
+	for _, file := range files {
+		if !analyzer.HasExtension(file, ".go") {
+			other++
+		}
+	}
+	if other == 0 {
+		return
+	}
+	rules := "counting rules"
+	if countSpec != "" && countSpec != analyzer.DefaultSpec {
+		rules = "the rules of --spec " + countSpec
+	}
+	logWarnf("%s only apply to Go files, %d other files are counted with the default rules", rules, other)
+}
+
+// validateSpec checks the --spec flag value
+func validateSpec() error {
+	if countSpec == "" {
+		return nil
+	}
+	_, err := analyzer.SpecConfig(countSpec)
+	return err
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureLog redirects the diagnostics to a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	saved := logger
+	logger = slog.New(newLineHandler(&buf, logLevel))
+	t.Cleanup(func() { logger = saved })
+	return &buf
+}
+
+func TestWarnGoOnlyRules(t *testing.T) {
+	t.Cleanup(func() { countReturns, countSpec = false, "" })
+
+	tests := []struct {
+		name    string
+		returns bool
+		spec    string
+		files   []string
+		warning string
+	}{
+		{"no rules", false, "", []string{"a.go", "b.py"}, ""},
+		{"default spec", false, "default", []string{"a.go", "b.py"}, ""},
+		{"Go files only", true, "", []string{"a.go", "b.go"}, ""},
+		{"flag with other files", true, "", []string{"a.go", "b.py", "c.ts"}, "2 other files"},
+		{"spec with other files", false, "fitzpatrick", []string{"b.c"}, "--spec fitzpatrick only apply to Go files, 1 other files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := captureLog(t)
+			countReturns, countSpec = tt.returns, tt.spec
+			warnGoOnlyRules(tt.files)
+
+			if tt.warning == "" {
+				if log.Len() > 0 {
+					t.Errorf("unexpected warning: %s", log)
+				}
+			} else if !strings.Contains(log.String(), tt.warning) {
+				t.Errorf("got %q, want a warning about %s", log, tt.warning)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisTimeoutHasNoHeader(t *testing.T) {
+	savedTimeout, savedByFunction := analyzeTimeout, byFunction
+	t.Cleanup(func() { analyzeTimeout, byFunction = savedTimeout, savedByFunction })
+	setFlags(t, func() { format = formatText })
+	// The per-function breakdown has no time left after the file analysis,
+	// so the file always times out
+	analyzeTimeout, byFunction = time.Nanosecond, true
+
+	out := captureOutput(t)
+	log := captureLog(t)
+	results, _, failures, err := runAnalysis([]string{fixture("test.go")}, false, nil)
+	if err != nil {
+		t.Fatalf("runAnalysis: %v", err)
+	}
+	if len(results) != 0 || len(failures) != 0 {
+		t.Errorf("got %d results and %d failures, want the file skipped", len(results), len(failures))
+	}
+	if out.Len() > 0 {
+		t.Errorf("got output %q, want none for a skipped file", out)
+	}
+	if !strings.Contains(log.String(), "exceeded") {
+		t.Errorf("got log %q, want a timeout warning", log)
+	}
+}
+
+func TestRunAnalysisByFunctionWithoutFunctions(t *testing.T) {
+	saved := byFunction
+	t.Cleanup(func() { byFunction = saved })
+	setFlags(t, func() { format = formatText })
+	byFunction = true
+
+	out := captureOutput(t)
+	if _, _, _, err := runAnalysis([]string{fixture("counting.py")}, false, nil); err != nil {
+		t.Fatalf("runAnalysis: %v", err)
+	}
+	if strings.Contains(out.String(), "Functions:") {
+		t.Errorf("got a functions heading for a language without per-function metrics:\n%s", out)
+	}
+}
+
+func TestRunAnalysisFailureHasNoHeader(t *testing.T) {
+	setFlags(t, func() { format = formatText })
+	unsupported := filepath.Join("..", "..", "..", "LICENSE")
+
+	out := captureOutput(t)
+	captureLog(t)
+	results, _, failures, err := runAnalysis([]string{fixture("slices.go"), unsupported}, true, nil)
+	if err != nil {
+		t.Fatalf("runAnalysis: %v", err)
+	}
+	if len(results) != 1 || len(failures) != 1 {
+		t.Fatalf("got %d results and %d failures, want 1 of each", len(results), len(failures))
+	}
+	if strings.Count(out.String(), "Analyzing file:") != 1 || strings.Contains(out.String(), "LICENSE") {
+		t.Errorf("got output %q, want a header for slices.go only", out)
+	}
+}
+
+// TestMain keeps the tests away from the user's result cache
+func TestMain(m *testing.M) {
+	noCache = true
+	os.Exit(m.Run())
+}
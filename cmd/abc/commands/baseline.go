@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"os"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	baselineWritePath string
+)
+
+func init() {
+	addAnalysisFlags(baselineCmd)
+	baselineCmd.Flags().StringVar(&baselineWritePath, "write", "", "Path of the baseline file to write")
+	baselineCmd.MarkFlagRequired("write")
+	baselineCmd.MarkFlagFilename("write", "json")
+}
+
+// baselineCmd represents the baseline command
+var baselineCmd = &cobra.Command{
+	Use:   "baseline [path...]",
+	Short: "Record the current score of every file as a baseline",
+	Long: `Analyze files or directories and record the ABC score of every file in
+a baseline file. Pass the baseline to 'analyze --baseline' later to report
+only the files whose score grew since it was written, so existing
+complexity can be paid down gradually while new regressions fail CI.
+
+Files are recorded under their reported path, relative to the working
+directory or --relative-to, so run 'analyze --baseline' from the same
+directory or with the same --relative-to.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		paths := args
+		if filePath != "" {
+			paths = append([]string{filePath}, args...)
+		}
+		if len(paths) == 0 {
+			logErrorf("file path is required")
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		if jobs < 1 {
+			logErrorf("--jobs must be at least 1, got %d", jobs)
+			os.Exit(1)
+		}
+
+		files, _, failed, err := expandPaths(paths)
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		warnGoOnlyRules(files)
+
+		var results []report.FileResult
+		for outcome := range analyzeFiles(files, jobs, nil) {
+			var timeoutErr *analyzer.TimeoutError
+			if errors.As(outcome.err, &timeoutErr) {
+				logWarnf("analysis of %s exceeded %s, skipping", outcome.path, analyzeTimeout)
+				continue
+			}
+			if outcome.err != nil {
+				logErrorf("analyzing %s: %v", outcome.path, outcome.err)
+				failed = true
+				continue
+			}
+			results = append(results, outcome.result)
+		}
+
+		// A partial baseline would report the missing files as new later on
+		if failed {
+			os.Exit(1)
+		}
+
+		if err := report.WriteBaseline(baselineWritePath, report.NewBaseline(results, scoreWeights())); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		logInfof("Baseline of %d files written to %s", len(results), baselineWritePath)
+	},
+}
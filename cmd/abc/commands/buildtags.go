@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"go/build"
+	"path/filepath"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+)
+
+// matchesBuild reports whether the Go file at path is part of the build for
+// the target platform, taken from the GOOS and GOARCH environment variables
+// like the go command does, with the build tags given by --tags. Both
+// //go:build lines (or the older // +build lines) and _GOOS/_GOARCH file
+// name suffixes are evaluated. Other files and files that cannot be read are
+// always considered part of the build.
+func matchesBuild(path string) bool {
+	if !analyzer.HasExtension(path, ".go") {
+		return true
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = buildTags
+	match, err := ctx.MatchFile(filepath.Dir(path), filepath.Base(path))
+	return match || err != nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestMatchesBuild(t *testing.T) {
+	t.Cleanup(func() { buildTags = nil })
+
+	tests := []struct {
+		file string
+		tags []string
+		want bool
+	}{
+		{"buildtags_extra.go", nil, false},
+		{"buildtags_extra.go", []string{"abc_extra"}, true},
+		{"buildtags_extra.go", []string{"other"}, false},
+		{"buildtags_windows.go", nil, runtime.GOOS == "windows"},
+		{"test.go", nil, true},
+		{"counting.py", nil, true},
+		{"missing.go", nil, true},
+	}
+
+	for _, tt := range tests {
+		buildTags = tt.tags
+		if got := matchesBuild(fixture(tt.file)); got != tt.want {
+			t.Errorf("%s with tags %v: got %v, want %v", tt.file, tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestCollectFilesSkipsExcludedBuilds(t *testing.T) {
+	t.Cleanup(func() { buildTags = nil })
+
+	for _, tags := range [][]string{nil, {"abc_extra"}} {
+		buildTags = tags
+		files, err := collectFiles(fixture(""), -1)
+		if err != nil {
+			t.Fatalf("collectFiles: %v", err)
+		}
+
+		want := tags != nil
+		if got := slices.Contains(files, filepath.Join(fixture(""), "buildtags_extra.go")); got != want {
+			t.Errorf("tags %v: buildtags_extra.go listed=%v, want %v", tags, got, want)
+		}
+		if !slices.Contains(files, filepath.Join(fixture(""), "test.go")) {
+			t.Errorf("tags %v: test.go not listed", tags)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/cache"
+	"github.com/abc-metrics/abc/internal/report"
+)
+
+var (
+	// resultCache is opened on first use, nil when caching is disabled or
+	// the cache directory is unusable
+	resultCache     *cache.Cache
+	resultCacheOnce sync.Once
+)
+
+// openCache returns the result cache for the current counting rules, opening
+// it on first use. Problems with the cache directory are logged and disable
+// caching instead of failing the analysis.
+func openCache() *cache.Cache {
+	resultCacheOnce.Do(func() {
+		if noCache {
+			return
+		}
+
+		dir := cacheDir
+		if dir == "" {
+			var err error
+			if dir, err = cache.DefaultDir(); err != nil {
+				logDebugf("caching disabled: %v", err)
+				return
+			}
+		}
+
+		// Results depend on the analyzer version and every counting rule, so
+		// all of them are part of the key
+		key := fmt.Sprintf("v%d %+v byFunction=%t", analyzer.Version, countConfig(), byFunction)
+		c, err := cache.Open(dir, key)
+		if err != nil {
+			logWarnf("caching disabled: %v", err)
+			return
+		}
+		resultCache = c
+	})
+	return resultCache
+}
+
+// analyzeCached returns the cached result of the file when its content has
+// not changed since it was cached, and analyzes and caches it otherwise.
+// Whenever the content is hashed, the result carries its hash, the same one
+// the cache matches entries by; with --include-source-hash it is hashed even
+// when caching is disabled. Either way the file is read once and the hashed
+// content is the analyzed one.
+func analyzeCached(path string) (report.FileResult, error) {
+	c := openCache()
+	if c == nil && !includeSourceHash {
+		return analyzeFile(path)
+	}
+
+	// Unsupported files fail before they are read, like in analyzeFile
+	fileAnalyzer, err := analyzer.GetAnalyzerForFileWithConfig(path, countConfig())
+	if err != nil {
+		return report.FileResult{}, err
+	}
+	content, err := analyzer.ReadSource(path)
+	if err != nil {
+		return report.FileResult{}, err
+	}
+	hash := cache.ContentHash(content)
+	if c != nil {
+		if result, ok := c.Get(path, content); ok {
+			logDebugf("using cached result for %s", path)
+			result.SourceHash = hash
+			return result, nil
+		}
+	}
+
+	// The content read for the hash is analyzed as is, so the hash always
+	// matches what was counted
+	result, err := analyzeSource(fileAnalyzer, path, content)
+	if err != nil {
+		return result, err
+	}
+	result.SourceHash = hash
+	if c == nil {
+		return result, nil
+	}
+	if err := c.Put(path, content, result); err != nil {
+		logDebugf("caching %s: %v", path, err)
+	}
+	return result, nil
+}
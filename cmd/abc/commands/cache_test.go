@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abc-metrics/abc/internal/cache"
+)
+
+// useCache points the result cache at a fresh directory for the rest of the
+// test, with debug messages captured
+func useCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	savedDir, savedNoCache, savedLevel := cacheDir, noCache, logLevel.Level()
+	cacheDir, noCache = dir, false
+	resultCache, resultCacheOnce = nil, sync.Once{}
+	logLevel.Set(slog.LevelDebug)
+	t.Cleanup(func() {
+		cacheDir, noCache = savedDir, savedNoCache
+		resultCache, resultCacheOnce = nil, sync.Once{}
+		logLevel.Set(savedLevel)
+	})
+	return dir
+}
+
+func TestAnalyzeCached(t *testing.T) {
+	useCache(t)
+	log := captureLog(t)
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	src := []byte("package main\n\nfunc main() {\n\tx := 1\n\tif x > 0 {\n\t\tprintln(x)\n\t}\n}\n")
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := analyzeCached(path)
+	if err != nil {
+		t.Fatalf("analyzeCached: %v", err)
+	}
+	if first.SourceHash != cache.ContentHash(src) {
+		t.Errorf("got source hash %q, want the hash of the analyzed content", first.SourceHash)
+	}
+	if m := first.Metrics; m.Assignments != 1 || m.Branches != 1 || m.Conditions != 1 {
+		t.Errorf("got A=%d B=%d C=%d, want A=1 B=1 C=1", m.Assignments, m.Branches, m.Conditions)
+	}
+
+	second, err := analyzeCached(path)
+	if err != nil {
+		t.Fatalf("analyzeCached: %v", err)
+	}
+	if !strings.Contains(log.String(), "using cached result") {
+		t.Errorf("second analysis did not use the cache, log: %s", log)
+	}
+	if !reflect.DeepEqual(first.Metrics, second.Metrics) || first.SourceHash != second.SourceHash {
+		t.Errorf("cached result differs from the analyzed one")
+	}
+
+	if _, err := analyzeCached(filepath.Join(t.TempDir(), "missing.go")); err == nil || !strings.Contains(err.Error(), "error reading file") {
+		t.Errorf("missing file: got error %v, want a read error", err)
+	}
+}
+
+func TestAnalyzeCachedSourceHashWithoutCache(t *testing.T) {
+	savedNoCache, savedHash := noCache, includeSourceHash
+	noCache, includeSourceHash = true, true
+	resultCache, resultCacheOnce = nil, sync.Once{}
+	t.Cleanup(func() {
+		noCache, includeSourceHash = savedNoCache, savedHash
+		resultCache, resultCacheOnce = nil, sync.Once{}
+	})
+
+	path := fixture("test.go")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := analyzeCached(path)
+	if err != nil {
+		t.Fatalf("analyzeCached: %v", err)
+	}
+	if result.SourceHash != cache.ContentHash(src) {
+		t.Errorf("got source hash %q, want the hash of the analyzed content", result.SourceHash)
+	}
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+)
+
+// Values of the --color flag
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// ANSI escape sequences used to color the text output
+const (
+	ansiReset   = "\033[0m"
+	ansiRed     = "\033[31m"
+	ansiBoldRed = "\033[1;31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+)
+
+// colorEnabled is set by setupColor when the text output is colored
+var colorEnabled bool
+
+// setupColor decides whether the text output is colored. With auto, colors
+// are used when stdout is a terminal, no --output file is set and the
+// NO_COLOR environment variable is empty or unset.
+func setupColor() error {
+	switch colorMode {
+	case colorAlways:
+		colorEnabled = true
+	case colorNever:
+		colorEnabled = false
+	case colorAuto:
+		colorEnabled = os.Getenv("NO_COLOR") == "" && outputPath == "" && isTerminal(os.Stdout)
+	default:
+		return fmt.Errorf("unsupported color mode %q (expected auto, always or never)", colorMode)
+	}
+	return nil
+}
+
+// colorize wraps s in the given escape sequence when colors are enabled
+func colorize(s, color string) string {
+	if !colorEnabled {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// colorSeverity colors a severity level: Low green, Medium yellow, High red
+// and Very High bold red
+func colorSeverity(level string) string {
+	switch level {
+	case "Low":
+		return colorize(level, ansiGreen)
+	case "Medium":
+		return colorize(level, ansiYellow)
+	case "High":
+		return colorize(level, ansiRed)
+	default:
+		return colorize(level, ansiBoldRed)
+	}
+}
+
+// colorScore colors a score line red when the score is above --threshold
+func colorScore(line string, score float64) string {
+	if threshold > 0 && score > threshold {
+		return colorize(line, ansiRed)
+	}
+	return line
+}
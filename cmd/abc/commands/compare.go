@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	compareTolerance float64
+)
+
+func init() {
+	compareCmd.Flags().Float64Var(&compareTolerance, "tolerance", 0, "How much a file's score may grow before it counts as a regression")
+}
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare old.json new.json",
+	Short: "Compare two JSON reports and show per-file deltas",
+	Long: `Compare two reports written by 'analyze --format json', for example before
+and after a refactoring or from two releases. Files are matched by path and
+every file whose metrics changed is listed with the change in assignments,
+branches, conditions and score. Files found in only one of the reports are
+listed as added or removed. A summary with the combined totals of both
+reports follows.
+
+A file regressed when its score grew by more than --tolerance; if any file
+regressed, the command exits with code 2. Added files do not count as
+regressions. With --format json the full comparison, unchanged files
+included, is written as JSON.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeJSONFiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		if format != formatText && format != formatJSON {
+			logErrorf("compare supports the text and json formats, got %q", format)
+			os.Exit(1)
+		}
+
+		before, err := report.ReadJSONReport(args[0])
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		after, err := report.ReadJSONReport(args[1])
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		comparison := report.Compare(before, after, compareTolerance, scoreWeights())
+
+		closeOutput, err := openOutput()
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if format == formatJSON {
+			err = report.WriteComparisonJSON(stdout, comparison)
+		} else {
+			printComparison(comparison)
+		}
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if err := closeOutput(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		if comparison.Regressed > 0 {
+			os.Exit(exitThreshold)
+		}
+	},
+}
+
+// printComparison prints the changed, added and removed files of the
+// comparison followed by a summary
+func printComparison(c report.Comparison) {
+	for _, delta := range c.Files {
+		switch delta.Status {
+		case report.StatusAdded:
+			fmt.Fprintf(stdout, "%-10s %s: A=%d, B=%d, C=%d, score %.2f\n", delta.Status, delta.Path,
+				delta.New.Assignments, delta.New.Branches, delta.New.Conditions, delta.New.Score)
+		case report.StatusRemoved:
+			fmt.Fprintf(stdout, "%-10s %s: score was %.2f\n", delta.Status, delta.Path, delta.Old.Score)
+		default:
+			o, n := delta.Old, delta.New
+			if o.Assignments == n.Assignments && o.Branches == n.Branches && o.Conditions == n.Conditions && o.Score == n.Score {
+				continue
+			}
+			status := fmt.Sprintf("%-10s", delta.Status)
+			switch delta.Status {
+			case report.StatusRegressed:
+				status = colorize(status, ansiRed)
+			case report.StatusImproved:
+				status = colorize(status, ansiGreen)
+			}
+			fmt.Fprintf(stdout, "%s %s: A=%d (%+d), B=%d (%+d), C=%d (%+d), score %.2f (%+.2f)\n", status, delta.Path,
+				n.Assignments, n.Assignments-o.Assignments,
+				n.Branches, n.Branches-o.Branches,
+				n.Conditions, n.Conditions-o.Conditions,
+				n.Score, n.Score-o.Score)
+		}
+	}
+
+	if len(c.Files) > 0 {
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, "Files: %d regressed, %d improved, %d unchanged, %d added, %d removed\n",
+		c.Regressed, c.Improved, c.Unchanged, c.Added, c.Removed)
+	fmt.Fprintf(stdout, "Total: A=%d (%+d), B=%d (%+d), C=%d (%+d), score %.2f (%+.2f)\n",
+		c.New.Assignments, c.New.Assignments-c.Old.Assignments,
+		c.New.Branches, c.New.Branches-c.Old.Branches,
+		c.New.Conditions, c.New.Conditions-c.Old.Conditions,
+		c.New.Score, c.New.Score-c.Old.Score)
+}
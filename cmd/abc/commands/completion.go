@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Shell completion scripts are generated by the completion command cobra
+// adds to the root command. The helpers below let flags taking one of a
+// fixed set of values, or a path, offer those as completions.
+
+// completeValues makes the flag of cmd complete to the given values instead
+// of file names
+func completeValues(cmd *cobra.Command, flag string, values ...string) {
+	cmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// completeJSONFiles makes the positional arguments of cmd complete to JSON
+// files
+func completeJSONFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"json"}, cobra.ShellCompDirectiveFilterFileExt
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/abc-metrics/abc/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// ignorePatterns are the gitignore-style patterns of paths skipped while
+// walking directories, taken from the config file
+var ignorePatterns []string
+
+// applyConfig loads the config file and uses its values as defaults for every
+// flag that was not set explicitly on the command line. Precedence, highest
+// first: command-line flags, the config file, built-in defaults.
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Verbose != nil {
+		if err := setDefault(cmd, "verbose", strconv.FormatBool(*cfg.Verbose)); err != nil {
+			return err
+		}
+	}
+	if cfg.Format != "" {
+		if err := setDefault(cmd, "format", cfg.Format); err != nil {
+			return err
+		}
+	}
+	if cfg.Threshold != nil {
+		if err := setDefault(cmd, "threshold", strconv.FormatFloat(*cfg.Threshold, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+	for name, value := range map[string]*float64{
+		"severity-low":    cfg.Severity.Low,
+		"severity-medium": cfg.Severity.Medium,
+		"severity-high":   cfg.Severity.High,
+		"weight-a":        cfg.Weights.A,
+		"weight-b":        cfg.Weights.B,
+		"weight-c":        cfg.Weights.C,
+	} {
+		if value == nil {
+			continue
+		}
+		if err := setDefault(cmd, name, strconv.FormatFloat(*value, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+	ignorePatterns = cfg.Ignore
+
+	return nil
+}
+
+// setDefault sets a flag from the config file unless it was given on the
+// command line. Flags the command does not have are left alone.
+func setDefault(cmd *cobra.Command, name, value string) error {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Changed {
+		return nil
+	}
+	return flag.Value.Set(value)
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/report"
+)
+
+// lineRange is an inclusive range of line numbers
+type lineRange struct {
+	start, end int
+}
+
+// changedLines holds the lines of a file changed since a git ref. A nil
+// value means the whole file is new.
+type changedLines []lineRange
+
+// contains reports whether the line was changed
+func (c changedLines) contains(line int) bool {
+	if c == nil {
+		return true
+	}
+	for _, r := range c {
+		if line >= r.start && line <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// hunkHeader matches the new-file range of a unified diff hunk header,
+// e.g. "@@ -10,2 +12,3 @@"
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// gitChangedLines returns the lines of path added or modified since ref,
+// according to git diff. Files git does not track yet are entirely new.
+func gitChangedLines(ref, path string) (changedLines, error) {
+	tracked, err := exec.Command("git", "ls-files", "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git ls-files: %w", gitError(err))
+	}
+	if len(bytes.TrimSpace(tracked)) == 0 {
+		return nil, nil
+	}
+
+	out, err := exec.Command("git", "diff", "--unified=0", "--no-color", "--no-ext-diff", ref, "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git diff: %w", gitError(err))
+	}
+	return parseUnifiedDiff(out)
+}
+
+// parseUnifiedDiff collects the new-file line ranges of every hunk. Hunks
+// that only delete lines have a count of 0 and add no range.
+func parseUnifiedDiff(diff []byte) (changedLines, error) {
+	ranges := changedLines{}
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		m := hunkHeader.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		if count > 0 {
+			ranges = append(ranges, lineRange{start: start, end: start + count - 1})
+		}
+	}
+	return ranges, scanner.Err()
+}
+
+// restrictToChanges limits a result to the lines changed since ref
+func restrictToChanges(result report.FileResult, ref string) (report.FileResult, error) {
+	changed, err := gitChangedLines(ref, result.Path)
+	if err != nil {
+		return report.FileResult{}, err
+	}
+
+	result.Metrics = result.Metrics.FilterLines(changed.contains)
+	for i, fn := range result.Functions {
+		result.Functions[i].Metrics = fn.Metrics.FilterLines(changed.contains)
+	}
+	return result, nil
+}
+
+// gitError adds git's own error output to a failed command error
+func gitError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
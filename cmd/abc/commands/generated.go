@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+)
+
+// generatedMarker is the comment marking generated Go files, as documented
+// in https://go.dev/s/generatedcode
+var generatedMarker = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated reports whether the Go file at path carries the generated code
+// marker before its first non-comment, non-blank line. Other files and files
+// that cannot be read are never considered generated.
+func isGenerated(path string) bool {
+	if !analyzer.HasExtension(path, ".go") {
+		return false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case generatedMarker.MatchString(line):
+			return true
+		case inBlock:
+			inBlock = !strings.Contains(trimmed, "*/")
+		case trimmed == "" || strings.HasPrefix(trimmed, "//"):
+		case strings.HasPrefix(trimmed, "/*"):
+			inBlock = !strings.Contains(trimmed[2:], "*/")
+		default:
+			// The package clause or other code ends the header
+			return false
+		}
+	}
+	return false
+}
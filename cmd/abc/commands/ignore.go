@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFile is the name of the gitignore-style file read from the root of
+// every walked directory
+const ignoreFile = ".abcignore"
+
+// skipTestsPatterns are the patterns enabled by --skip-tests
+var skipTestsPatterns = []string{"vendor/", "*_test.go"}
+
+// ignoreRule is a single gitignore-style pattern
+type ignoreRule struct {
+	pattern  string // Slash-separated glob, "**" matches any number of directories
+	negate   bool   // Pattern started with "!", matching paths are included again
+	dirOnly  bool   // Pattern ended with "/", only directories match
+	anchored bool   // Pattern contains a slash and is matched against the full relative path
+}
+
+// parseIgnoreRule parses one pattern line. It reports false for blank lines
+// and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	rule.pattern = line
+	return rule, line != ""
+}
+
+// matches reports whether the rule matches the slash-separated path relative
+// to the walked root
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.anchored {
+		return matchSegments(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+	}
+	return matchSegments([]string{r.pattern}, []string{path.Base(rel)})
+}
+
+// matchSegments matches path segments against pattern segments, where a "**"
+// segment matches zero or more path segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// ignoreRules is an ordered list of rules where the last matching rule wins
+type ignoreRules []ignoreRule
+
+// ignored reports whether the slash-separated relative path is ignored
+func (rules ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.matches(rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreRules parses a list of patterns, skipping blanks and comments
+func parseIgnoreRules(patterns []string) ignoreRules {
+	var rules ignoreRules
+	for _, pattern := range patterns {
+		if rule, ok := parseIgnoreRule(pattern); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// walkIgnoreRules builds the rules for walking root: --skip-tests defaults,
+// config file patterns, the root's .abcignore file and --exclude patterns,
+// in that order so later sources can override earlier ones
+func walkIgnoreRules(root string) (ignoreRules, error) {
+	var patterns []string
+	if skipTests {
+		patterns = append(patterns, skipTestsPatterns...)
+	}
+	patterns = append(patterns, ignorePatterns...)
+
+	filePatterns, err := readIgnoreFile(filepath.Join(root, ignoreFile))
+	if err != nil {
+		return nil, err
+	}
+	patterns = append(patterns, filePatterns...)
+	patterns = append(patterns, excludePatterns...)
+
+	return parseIgnoreRules(patterns), nil
+}
+
+// readIgnoreFile returns the lines of an ignore file, or nothing if it does
+// not exist
+func readIgnoreFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// logLevel is the minimum level of the diagnostics written to stderr. It is
+// Info by default, Debug with --verbose and Error with --quiet.
+var logLevel = new(slog.LevelVar)
+
+// logger writes diagnostics, such as errors, warnings and progress, to
+// stderr. Results go to stdout (or --output) and never through the logger.
+var logger = slog.New(newLineHandler(os.Stderr, logLevel))
+
+// setupLogging applies --verbose and --quiet to the log level
+func setupLogging() {
+	switch {
+	case quiet:
+		logLevel.Set(slog.LevelError)
+	case verbose:
+		logLevel.Set(slog.LevelDebug)
+	}
+}
+
+// logErrorf logs a formatted message at the Error level
+func logErrorf(format string, args ...any) {
+	logf(slog.LevelError, format, args...)
+}
+
+// logWarnf logs a formatted message at the Warn level
+func logWarnf(format string, args ...any) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// logInfof logs a formatted message at the Info level
+func logInfof(format string, args ...any) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// logDebugf logs a formatted message at the Debug level, shown with --verbose
+func logDebugf(format string, args ...any) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// logf logs a formatted message at the given level. The message is only
+// formatted when the level is enabled.
+func logf(level slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
+
+// lineHandler is a slog.Handler writing one plain line per record, prefixed
+// with the level ("Error: ", "Warning: ", "Debug: "; none for Info) and
+// followed by the record's attributes as key=value pairs. Groups are not
+// used by the commands and are ignored.
+type lineHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+}
+
+// newLineHandler returns a handler writing records at or above level to w
+func newLineHandler(w io.Writer, level slog.Leveler) *lineHandler {
+	return &lineHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+// Enabled implements slog.Handler
+func (h *lineHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler
+func (h *lineHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf []byte
+	switch {
+	case r.Level >= slog.LevelError:
+		buf = append(buf, "Error: "...)
+	case r.Level >= slog.LevelWarn:
+		buf = append(buf, "Warning: "...)
+	case r.Level < slog.LevelInfo:
+		buf = append(buf, "Debug: "...)
+	}
+	buf = append(buf, r.Message...)
+
+	appendAttr := func(a slog.Attr) bool {
+		buf = fmt.Appendf(buf, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		appendAttr(a)
+	}
+	r.Attrs(appendAttr)
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+// WithAttrs implements slog.Handler
+func (h *lineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+// WithGroup implements slog.Handler
+func (h *lineHandler) WithGroup(string) slog.Handler {
+	return h
+}
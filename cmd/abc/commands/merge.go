@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	mergeConflict string
+)
+
+func init() {
+	mergeCmd.Flags().StringVar(&mergeConflict, "on-conflict", report.MergeLast, "How to resolve a file found in several reports: last, error or combine")
+	completeValues(mergeCmd, "on-conflict", report.MergeLast, report.MergeError, report.MergeCombine)
+}
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge report.json...",
+	Short: "Merge several JSON reports into one",
+	Long: `Merge reports written by 'analyze --format json', for example by CI jobs
+that each analyzed a shard of a repository, into a single report with the
+combined total recalculated over all files. The merged report is printed in
+the selected format, so it can be merged or compared again with --format json.
+
+Files found in only one report are taken as they are. A file found in
+several reports is resolved by --on-conflict:
+  last     the entry of the report given last wins (default)
+  error    merging fails
+  combine  the counts and lines are summed and the per-function metrics of
+           all entries are kept, as when combining the metrics of files
+
+Scores and severities are recalculated from the counts with the current
+--weight-* and --severity-* flags. The JSON and JSON Lines output include
+the total unless --include-total=false is given.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeJSONFiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		switch mergeConflict {
+		case report.MergeLast, report.MergeError, report.MergeCombine:
+		default:
+			logErrorf("unsupported --on-conflict rule %q (expected last, error or combine)", mergeConflict)
+			os.Exit(1)
+		}
+		if !cmd.Flags().Changed("include-total") && !cmd.Flags().Changed("with-totals") {
+			includeTotal = true
+		}
+
+		reports := make([][]report.FileResult, 0, len(args))
+		for _, path := range args {
+			results, err := report.ReadJSONFiles(path)
+			if err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			reports = append(reports, results)
+		}
+		merged, err := report.Merge(reports, mergeConflict)
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		closeOutput, err := openOutput()
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if err := printSavedResults(merged); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if err := closeOutput(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// jsonMeta describes the current run at the top of JSON output, nil when
+// runMeta leaves it out
+var jsonMeta *report.JSONMeta
+
+// runMeta returns the description of a run of cmd over the paths for the top
+// of JSON output, or nil. Runs over several files get it by default and runs
+// over a single file only with --with-meta; --with-meta=false and
+// --json-always-array, which promises a bare array, leave it out.
+func runMeta(cmd *cobra.Command, paths []string, multiple bool) *report.JSONMeta {
+	if withMeta || multiple && !jsonAlwaysArray && !cmd.Flags().Changed("with-meta") {
+		return newJSONMeta(cmd, paths)
+	}
+	return nil
+}
+
+// newJSONMeta describes a run of cmd over the paths. Options lists every flag
+// whose value differs from its default, whether it was given on the command
+// line or taken from the config file.
+func newJSONMeta(cmd *cobra.Command, paths []string) *report.JSONMeta {
+	options := map[string]string{}
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Value.String() != flag.DefValue {
+			options[flag.Name] = flag.Value.String()
+		}
+	})
+
+	return &report.JSONMeta{
+		Tool:            "abc",
+		Version:         toolVersion(),
+		AnalyzerVersion: analyzer.Version,
+		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		Paths:           paths,
+		Options:         options,
+	}
+}
+
+// toolVersion returns the module version the binary was built from, as
+// recorded by the go command, or "(devel)" when it is unknown
+func toolVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
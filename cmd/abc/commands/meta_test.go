@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunMeta(t *testing.T) {
+	tests := []struct {
+		name        string
+		multiple    bool
+		withMeta    string // Value given to --with-meta, "" when not given
+		alwaysArray bool
+		want        bool
+	}{
+		{"single file", false, "", false, false},
+		{"single file with meta", false, "true", false, true},
+		{"several files", true, "", false, true},
+		{"several files without meta", true, "false", false, false},
+		{"several files as array", true, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlags(t, func() { jsonAlwaysArray = tt.alwaysArray })
+			cmd := &cobra.Command{}
+			cmd.Flags().BoolVar(&withMeta, "with-meta", false, "")
+			if tt.withMeta != "" {
+				if err := cmd.Flags().Set("with-meta", tt.withMeta); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			meta := runMeta(cmd, []string{"."}, tt.multiple)
+			if got := meta != nil; got != tt.want {
+				t.Errorf("got meta %v, want %v", got, tt.want)
+			}
+			if meta != nil && (meta.Tool != "abc" || len(meta.Paths) != 1) {
+				t.Errorf("got %+v, want the tool and paths of the run", meta)
+			}
+		})
+	}
+}
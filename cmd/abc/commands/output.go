@@ -0,0 +1,580 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// Output formats
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatJSONL    = "jsonl"
+	formatCSV      = "csv"
+	formatSARIF    = "sarif"
+	formatHTML     = "html"
+	formatJUnit    = "junit"
+	formatMarkdown = "markdown"
+	formatTable    = "table"
+)
+
+// outputFormats lists every output format, for help texts and completion
+var outputFormats = []string{formatText, formatJSON, formatJSONL, formatCSV, formatSARIF, formatHTML, formatJUnit, formatMarkdown, formatTable}
+
+// Detail list formats of the text output
+const (
+	detailsPretty  = "pretty"
+	detailsCompact = "compact"
+	detailsJSON    = "json"
+)
+
+// stdout receives all regular output. It is replaced by a file with --output.
+var stdout io.Writer = os.Stdout
+
+// openOutput redirects regular output to the --output file, creating parent
+// directories as needed. The returned function closes the file and must be
+// called once all output has been written.
+func openOutput() (func() error, error) {
+	if outputPath == "" {
+		return func() error { return nil }, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating output directory: %w", err)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("error creating output file: %w", err)
+	}
+
+	stdout = file
+	return func() error {
+		stdout = os.Stdout
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("error writing output file: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// validateFormat checks the --format flag value
+func validateFormat() error {
+	switch format {
+	case formatText, formatJSON, formatJSONL, formatCSV, formatSARIF, formatHTML, formatJUnit, formatMarkdown, formatTable:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q (expected text, json, jsonl, csv, sarif, html, junit, markdown or table)", format)
+	}
+}
+
+// validateFlattenDetails checks that --flatten-details is only used with
+// the json format and without the flags adding parts a flat array of
+// details has no room for
+func validateFlattenDetails() error {
+	switch {
+	case !flattenDetails:
+		return nil
+	case format != formatJSON:
+		return fmt.Errorf("--flatten-details requires --format json, got %q", format)
+	case includeTotal:
+		return errors.New("--flatten-details cannot be combined with --include-total or --with-totals")
+	case withMeta:
+		return errors.New("--flatten-details cannot be combined with --with-meta")
+	}
+	return nil
+}
+
+// validateJSONAlwaysArray checks that --json-always-array is not combined
+// with the flags that turn json output into an object
+func validateJSONAlwaysArray() error {
+	switch {
+	case !jsonAlwaysArray || format != formatJSON:
+		return nil
+	case includeTotal:
+		return errors.New("--json-always-array cannot be combined with --include-total or --with-totals")
+	case withMeta:
+		return errors.New("--json-always-array cannot be combined with --with-meta")
+	}
+	return nil
+}
+
+// validateDetailsFormat checks the --details-format flag value
+func validateDetailsFormat() error {
+	switch detailsFormat {
+	case detailsPretty, detailsCompact, detailsJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported details format %q (expected pretty, compact or json)", detailsFormat)
+	}
+}
+
+// severityConfig builds the severity cutoffs from the command-line flags
+func severityConfig() metrics.SeverityConfig {
+	return metrics.SeverityConfig{Low: severityLow, Medium: severityMedium, High: severityHigh}
+}
+
+// scoreWeights builds the score weights from the command-line flags
+func scoreWeights() metrics.Weights {
+	return metrics.Weights{A: weightA, B: weightB, C: weightC}
+}
+
+// score calculates the ABC score of the metrics with the configured weights
+func score(m metrics.ABCMetrics) float64 {
+	return m.ScoreWith(scoreWeights())
+}
+
+// displayPath returns the path as reported in the output: relative to
+// --relative-to, or the working directory by default, with forward slashes.
+// Paths outside that directory are reported as absolute paths.
+func displayPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	base := relativeTo
+	if base == "" {
+		if base, err = os.Getwd(); err != nil {
+			return filepath.ToSlash(abs)
+		}
+	}
+	if base, err = filepath.Abs(base); err != nil {
+		return filepath.ToSlash(abs)
+	}
+
+	rel, err := filepath.Rel(base, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// printResults prints the results in the selected structured format, with
+// the total when it is not nil. Failures are only included in the JSON
+// format, where a single result is an array when asArray or
+// --json-always-array is set. --json-always-array keeps the array even when
+// files failed, so failures are then only reported on stderr. Text output is
+// printed while analyzing, so it is not handled here.
+func printResults(results []report.FileResult, asArray bool, total *report.Total, failures []report.Failure) error {
+	switch format {
+	case formatJSON:
+		if flattenDetails {
+			// Failures were reported on stderr as they came up
+			if len(failures) > 0 {
+				logWarnf("the --flatten-details output leaves out %s that failed", pluralFiles(len(failures)))
+			}
+			return report.WriteFlatDetailsJSON(stdout, results)
+		}
+		if jsonAlwaysArray {
+			// Failures were reported on stderr as they came up
+			failures = nil
+		}
+		return report.WriteJSON(stdout, results, asArray || jsonAlwaysArray, jsonOptions(), total, failures, scoreWeights(), severityConfig())
+	case formatJSONL:
+		return report.WriteJSONL(stdout, results, jsonOptions(), total, scoreWeights(), severityConfig())
+	case formatCSV:
+		return report.WriteCSV(stdout, results, hasFunctions(results), total, csvFields, scoreWeights(), severityConfig())
+	case formatSARIF:
+		return report.WriteSARIF(stdout, results, hasFunctions(results), threshold, scoreWeights(), severityConfig())
+	case formatHTML:
+		return report.WriteHTML(stdout, results, htmlTheme, scoreWeights(), severityConfig())
+	case formatJUnit:
+		return report.WriteJUnit(stdout, results, threshold, scoreWeights(), severityConfig())
+	case formatMarkdown:
+		return report.WriteMarkdown(stdout, results, showDetails, scoreWeights(), severityConfig())
+	case formatTable:
+		printTable(results, total)
+	}
+	return nil
+}
+
+// jsonOptions selects the optional parts of the JSON and JSON Lines output
+// from the command-line flags
+func jsonOptions() report.JSONOptions {
+	return report.JSONOptions{Details: showDetails, Formula: !noFormula, SourceHash: includeSourceHash, Meta: jsonMeta}
+}
+
+// runTotal reports whether the output of a run of cmd includes the total.
+// JSON and CSV output of several files get it by default, as the total is
+// the figure a health check looks at first; --include-total=false leaves it
+// out. By default it is also left out where it has no place: with
+// --json-always-array, --flatten-details or CSV fields without path.
+func runTotal(cmd *cobra.Command, multiple bool) bool {
+	if includeTotal || cmd.Flags().Changed("include-total") || cmd.Flags().Changed("with-totals") {
+		return includeTotal
+	}
+
+	switch {
+	case !multiple || flattenDetails:
+		return false
+	case format == formatJSON:
+		return !jsonAlwaysArray
+	case format == formatCSV:
+		return report.ValidateCSVTotal(csvFields, true) == nil
+	}
+	return false
+}
+
+// structuredTotal returns the total of the results for the JSON, JSON Lines
+// and CSV output, or nil unless --include-total is set or runTotal turned it
+// on. The table output gets
+// it for its footer with --summary-footer.
+func structuredTotal(results []report.FileResult) *report.Total {
+	if !includeTotal && !(format == formatTable && summaryFooter) {
+		return nil
+	}
+	total := report.NewTotal(results)
+	return &total
+}
+
+// hasFunctions reports whether any result includes a per-function breakdown
+func hasFunctions(results []report.FileResult) bool {
+	for _, result := range results {
+		if len(result.Functions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// printScore prints the counts, score and severity of the given metrics
+func printScore(abcMetrics metrics.ABCMetrics) {
+	fmt.Fprintln(stdout, scoreLine(abcMetrics))
+	fmt.Fprintf(stdout, "Complexity: %s\n", colorSeverity(metrics.SeverityLevelWith(score(abcMetrics), severityConfig())))
+}
+
+// scoreLine formats the score and counts of the given metrics, colored when
+// the score is above --threshold, or only the counts with --no-score
+func scoreLine(m metrics.ABCMetrics) string {
+	if noScore {
+		return fmt.Sprintf("A=%d B=%d C=%d", m.Assignments, m.Branches, m.Conditions)
+	}
+	return colorScore(m.StringWith(scoreWeights()), score(m))
+}
+
+// printTotal prints the combined metrics of several files
+func printTotal(fileCount int, combined metrics.ABCMetrics) {
+	fmt.Fprintf(stdout, "\nTotal (%s):\n", pluralFiles(fileCount))
+	printScore(combined)
+}
+
+// pluralFiles formats a number of files, as "1 file" or "3 files"
+func pluralFiles(count int) string {
+	if count == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", count)
+}
+
+// printSummary prints the combined metrics of all files followed by the top
+// highest-scoring files at or above --min-score, worst first. With --no-score
+// the files are still ranked by score but only their counts are shown.
+func printSummary(results []report.FileResult, combined metrics.ABCMetrics, top int) {
+	fmt.Fprintf(stdout, "Summary (%s):\n", pluralFiles(len(results)))
+	printScore(combined)
+
+	if top <= 0 {
+		return
+	}
+
+	// Files below --min-score are never listed
+	var sorted []report.FileResult
+	for _, result := range results {
+		if score(result.Metrics) >= minScore {
+			sorted = append(sorted, result)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return score(sorted[i].Metrics) > score(sorted[j].Metrics)
+	})
+	if len(sorted) == 0 {
+		return
+	}
+	if len(sorted) > top {
+		sorted = sorted[:top]
+	}
+
+	fmt.Fprintf(stdout, "\nTop %d files:\n", len(sorted))
+	for i, result := range sorted {
+		if noScore {
+			fmt.Fprintf(stdout, "  %d. %s: %s\n", i+1, result.Path, scoreLine(result.Metrics))
+		} else {
+			fmt.Fprintf(stdout, "  %d. %s: %.2f\n", i+1, result.Path, score(result.Metrics))
+		}
+	}
+}
+
+// printMetrics prints the metrics of the file at path in the text format
+func printMetrics(path string, abcMetrics metrics.ABCMetrics) {
+	printScore(abcMetrics)
+
+	if abcMetrics.Guards > 0 {
+		fmt.Fprintf(stdout, "Guard clauses: %d of %d conditions\n", abcMetrics.Guards, abcMetrics.Conditions)
+	}
+
+	if abcMetrics.MaxNesting > 0 {
+		fmt.Fprintf(stdout, "Max nesting depth: %d\n", abcMetrics.MaxNesting)
+	}
+
+	if verbose {
+		fmt.Fprintf(stdout, "Lines: %d\n", abcMetrics.Lines)
+		if abcMetrics.UniqueBranchesKnown() {
+			fmt.Fprintf(stdout, "Unique callees: %d of %d branches\n", abcMetrics.UniqueBranches(), abcMetrics.Branches)
+		}
+		fmt.Fprintf(stdout, "Decision density: %.2f conditions per 100 lines\n", abcMetrics.DecisionDensity())
+		fmt.Fprintf(stdout, "Score density: %.2f per 100 lines\n", abcMetrics.ScoreDensityWith(scoreWeights()))
+	}
+
+	if explain {
+		printExplanation(abcMetrics)
+	}
+
+	// If show details flag is set, print detailed metrics
+	if !showDetails {
+		return
+	}
+	switch detailsFormat {
+	case detailsCompact, detailsJSON:
+		printDetailStream(path, abcMetrics)
+	default:
+		printDetails("Assignments", abcMetrics.AssignmentList)
+		printDetails("Branches", abcMetrics.BranchList)
+		printDetails("Conditions", abcMetrics.ConditionList)
+	}
+}
+
+// explainTop is the number of entries listed per line by --explain
+const explainTop = 5
+
+// printExplanation summarizes what drives the score: its formula, the most
+// assigned variables, the most called functions and how many conditions of
+// each kind there are
+func printExplanation(m metrics.ABCMetrics) {
+	fmt.Fprintln(stdout, "\nExplanation:")
+	if !noScore {
+		fmt.Fprintf(stdout, "  Formula: %s\n", m.FormulaWith(scoreWeights()))
+	}
+	fmt.Fprintf(stdout, "  Top assignments: %s\n", joinTally(tallyDetails(m.AssignmentList), explainTop, "%s x%d"))
+	fmt.Fprintf(stdout, "  Top branch callees: %s\n", joinTally(tallyDetails(m.BranchList), explainTop, "%s x%d"))
+	fmt.Fprintf(stdout, "  Condition types: %s\n", joinTally(tallyDetails(m.ConditionList), 0, "%s=%d"))
+}
+
+// tally is the number of items counted for one detail text
+type tally struct {
+	text  string
+	count int
+}
+
+// tallyDetails counts the items of a detail list per text, most frequent
+// first and alphabetically among equal counts
+func tallyDetails(details []metrics.MetricDetail) []tally {
+	counts := map[string]int{}
+	for _, d := range details {
+		counts[d.Text] += d.Weight()
+	}
+
+	tallies := make([]tally, 0, len(counts))
+	for text, count := range counts {
+		tallies = append(tallies, tally{text: text, count: count})
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].count != tallies[j].count {
+			return tallies[i].count > tallies[j].count
+		}
+		return tallies[i].text < tallies[j].text
+	})
+	return tallies
+}
+
+// joinTally formats up to top tallies (all of them for 0) with the format,
+// which receives the text and the count, noting how many more there are
+func joinTally(tallies []tally, top int, format string) string {
+	if len(tallies) == 0 {
+		return "none"
+	}
+
+	shown := tallies
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+	parts := make([]string, 0, len(shown)+1)
+	for _, t := range shown {
+		parts = append(parts, fmt.Sprintf(format, t.text, t.count))
+	}
+	if len(shown) < len(tallies) {
+		parts = append(parts, fmt.Sprintf("%d more", len(tallies)-len(shown)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countedDetail is a detail list entry together with the number of
+// identical entries it stands for with --collapse
+type countedDetail struct {
+	metrics.MetricDetail
+	kind  string // A, B or C
+	count int
+}
+
+// collapseList sorts a detail list top to bottom. With --collapse, entries
+// with the same line, text and context are merged into one with a count.
+func collapseList(kind string, details []metrics.MetricDetail) []countedDetail {
+	sorted := metrics.SortDetails(details)
+	var entries []countedDetail
+	for i := 0; i < len(sorted); i++ {
+		entry := countedDetail{MetricDetail: sorted[i], kind: kind, count: 1}
+		for collapseDetails && i+1 < len(sorted) && sameDetail(sorted[i+1], entry.MetricDetail) {
+			entry.count++
+			i++
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// printDetails prints a detail list top to bottom under a title. With
+// --collapse, identical entries are printed once with a count.
+func printDetails(title string, details []metrics.MetricDetail) {
+	fmt.Fprintf(stdout, "\n%s:\n", title)
+
+	for n, entry := range collapseList("", details) {
+		if entry.count > 1 {
+			fmt.Fprintf(stdout, "  %d. Line %d: %s (%s) x%d\n", n+1, entry.Line, entry.Text, entry.Context, entry.count)
+		} else {
+			fmt.Fprintf(stdout, "  %d. Line %d: %s (%s)\n", n+1, entry.Line, entry.Text, entry.Context)
+		}
+	}
+}
+
+// jsonDetail is a detail printed with --details-format json
+type jsonDetail struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"` // A, B or C
+	Line    int    `json:"line"`
+	Col     int    `json:"col"`
+	Text    string `json:"text"`
+	Context string `json:"context"`
+	Count   int    `json:"count,omitempty"` // Number of identical entries, only set with --collapse
+}
+
+// printDetailStream prints the assignments, branches and conditions of the
+// file at path together, one per line in position order, for tools such as
+// grep and editor quickfix lists. The compact format prints
+// path:line:col: [A] text (context); the json format one JSON object per
+// line.
+func printDetailStream(path string, m metrics.ABCMetrics) {
+	entries := collapseList("A", m.AssignmentList)
+	entries = append(entries, collapseList("B", m.BranchList)...)
+	entries = append(entries, collapseList("C", m.ConditionList)...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Line != entries[j].Line {
+			return entries[i].Line < entries[j].Line
+		}
+		return entries[i].Col < entries[j].Col
+	})
+
+	if len(entries) > 0 {
+		fmt.Fprintln(stdout)
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	for _, entry := range entries {
+		if detailsFormat == detailsJSON {
+			detail := jsonDetail{Path: path, Kind: entry.kind, Line: entry.Line, Col: entry.Col, Text: entry.Text, Context: entry.Context}
+			if entry.count > 1 {
+				detail.Count = entry.count
+			}
+			encoder.Encode(detail)
+			continue
+		}
+
+		fmt.Fprintf(stdout, "%s:%d:%d: [%s] %s (%s)", path, entry.Line, entry.Col, entry.kind, entry.Text, entry.Context)
+		if entry.count > 1 {
+			fmt.Fprintf(stdout, " x%d", entry.count)
+		}
+		fmt.Fprintln(stdout)
+	}
+}
+
+// sameDetail reports whether two details only differ in their column
+func sameDetail(a, b metrics.MetricDetail) bool {
+	return a.Line == b.Line && a.Text == b.Text && a.Context == b.Context
+}
+
+// printWarnings prints lint warnings for a file to stderr
+func printWarnings(path string, abcMetrics metrics.ABCMetrics) {
+	for _, warning := range abcMetrics.Warnings {
+		logWarnf("%s:%d: %s: %s", path, warning.Line, warning.Text, warning.Context)
+	}
+}
+
+// printFunctions prints per-function metrics sorted by descending score.
+// With --verbose each function also gets its decision density.
+func printFunctions(functions []metrics.FunctionMetrics) {
+	fmt.Fprintln(stdout, "\nFunctions:")
+	for i, fn := range sortFunctions(functions) {
+		fmt.Fprintf(stdout, "  %d. %s (lines %d-%d): %s (%s)", i+1, fn.Name, fn.StartLine, fn.EndLine, scoreLine(fn.Metrics), colorSeverity(metrics.SeverityLevelWith(score(fn.Metrics), severityConfig())))
+		if verbose {
+			fmt.Fprintf(stdout, ", decision density %.2f", fn.Metrics.DecisionDensity())
+		}
+		fmt.Fprintln(stdout)
+	}
+}
+
+// sortFunctions returns a copy of the functions sorted by descending score,
+// keeping source order for equal scores
+func sortFunctions(functions []metrics.FunctionMetrics) []metrics.FunctionMetrics {
+	sorted := append([]metrics.FunctionMetrics(nil), functions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return score(sorted[i].Metrics) > score(sorted[j].Metrics)
+	})
+	return sorted
+}
+
+// validateGroupBy checks --group-by and the flags it cannot be combined with
+func validateGroupBy() error {
+	switch {
+	case groupBy == "":
+		return nil
+	case groupBy != "dir":
+		return fmt.Errorf("unsupported --group-by %q (expected dir)", groupBy)
+	case format != formatText && format != formatJSON:
+		return fmt.Errorf("--group-by supports the text and json formats, got %q", format)
+	case quiet || summaryOnly:
+		return fmt.Errorf("--group-by cannot be combined with --quiet or --summary")
+	case groupDepth < 0:
+		return fmt.Errorf("--group-depth must not be negative, got %d", groupDepth)
+	}
+	return nil
+}
+
+// printDirectories prints the combined metrics of each directory scoring at
+// least --min-score, one line per directory in the text format
+func printDirectories(dirs []report.DirectoryResult) error {
+	var shown []report.DirectoryResult
+	for _, dir := range dirs {
+		if score(dir.Metrics()) >= minScore {
+			shown = append(shown, dir)
+		}
+	}
+
+	if format == formatJSON {
+		return report.WriteDirectoriesJSON(stdout, shown, scoreWeights(), severityConfig())
+	}
+
+	fmt.Fprintln(stdout, "Directories:")
+	for _, dir := range shown {
+		m := dir.Metrics()
+		fmt.Fprintf(stdout, "  %s (%d files): %s (%s)\n", dir.Path, len(dir.Files), scoreLine(m), colorSeverity(metrics.SeverityLevelWith(score(m), severityConfig())))
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// captureOutput redirects the regular output to a buffer for the rest of the
+// test
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	saved := stdout
+	stdout = &buf
+	t.Cleanup(func() { stdout = saved })
+	return &buf
+}
+
+// setFlags sets command-line flag variables for the rest of the test,
+// restoring their previous values afterwards
+func setFlags(t *testing.T, set func()) {
+	t.Helper()
+	savedFormat, savedArray, savedTotal, savedMeta := format, jsonAlwaysArray, includeTotal, withMeta
+	savedFlatten, savedGroupBy, savedGroupDepth := flattenDetails, groupBy, groupDepth
+	savedQuiet, savedSummary, savedFields := quiet, summaryOnly, csvFields
+	t.Cleanup(func() {
+		format, jsonAlwaysArray, includeTotal, withMeta = savedFormat, savedArray, savedTotal, savedMeta
+		flattenDetails, groupBy, groupDepth = savedFlatten, savedGroupBy, savedGroupDepth
+		quiet, summaryOnly, csvFields = savedQuiet, savedSummary, savedFields
+	})
+	set()
+}
+
+// jsonShape decodes JSON output and names its top-level shape, along with
+// the keys of an object
+func jsonShape(t *testing.T, out []byte) (string, map[string]json.RawMessage) {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal(out, &value); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if _, ok := value.([]any); ok {
+		return "array", nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(out, &object); err != nil {
+		t.Fatalf("JSON output %q is neither an array nor an object", out)
+	}
+	return "object", object
+}
+
+func TestPrintResultsJSONFailures(t *testing.T) {
+	results := []report.FileResult{{Path: "a.go", Metrics: metrics.ABCMetrics{Assignments: 1}}}
+	failures := []report.Failure{{Path: "b.go", Error: "syntax error"}}
+
+	for _, alwaysArray := range []bool{false, true} {
+		setFlags(t, func() { format, jsonAlwaysArray = formatJSON, alwaysArray })
+		out := captureOutput(t)
+		if err := printResults(results, true, nil, failures); err != nil {
+			t.Fatalf("printResults: %v", err)
+		}
+
+		shape, object := jsonShape(t, out.Bytes())
+		switch {
+		case alwaysArray && shape != "array":
+			t.Errorf("--json-always-array: got %s, want array", shape)
+		case !alwaysArray && (shape != "object" || object["failures"] == nil):
+			t.Errorf("got %s %s, want an object with failures", shape, out)
+		}
+	}
+}
+
+func TestValidateJSONAlwaysArray(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		alwaysArray bool
+		total       bool
+		meta        bool
+		wantErr     bool
+	}{
+		{"array alone", formatJSON, true, false, false, false},
+		{"total without array", formatJSON, false, true, false, false},
+		{"array with total", formatJSON, true, true, false, true},
+		{"array with meta", formatJSON, true, false, true, true},
+		{"array with total for csv", formatCSV, true, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlags(t, func() {
+				format, jsonAlwaysArray, includeTotal, withMeta = tt.format, tt.alwaysArray, tt.total, tt.meta
+			})
+			if err := validateJSONAlwaysArray(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFlattenDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		total   bool
+		meta    bool
+		wantErr bool
+	}{
+		{"json", formatJSON, false, false, false},
+		{"csv", formatCSV, false, false, true},
+		{"with total", formatJSON, true, false, true},
+		{"with meta", formatJSON, false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlags(t, func() {
+				flattenDetails, format, includeTotal, withMeta = true, tt.format, tt.total, tt.meta
+			})
+			if err := validateFlattenDetails(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGroupBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy string
+		format  string
+		summary bool
+		depth   int
+		wantErr bool
+	}{
+		{"none", "", formatCSV, true, -1, false},
+		{"dir", "dir", formatText, false, 0, false},
+		{"dir as json", "dir", formatJSON, false, 2, false},
+		{"unknown grouping", "package", formatText, false, 0, true},
+		{"csv", "dir", formatCSV, false, 0, true},
+		{"with summary", "dir", formatText, true, 0, true},
+		{"negative depth", "dir", formatText, false, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlags(t, func() {
+				groupBy, format, summaryOnly, groupDepth = tt.groupBy, tt.format, tt.summary, tt.depth
+			})
+			if err := validateGroupBy(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintResultsTotals(t *testing.T) {
+	results := []report.FileResult{
+		{Path: "a.go", Metrics: metrics.ABCMetrics{Assignments: 3, Branches: 4}},
+		{Path: "b.go", Metrics: metrics.ABCMetrics{Conditions: 2}},
+	}
+
+	for _, tt := range []struct {
+		format string
+		total  bool
+	}{
+		{formatJSON, false},
+		{formatJSON, true},
+		{formatCSV, false},
+		{formatCSV, true},
+	} {
+		setFlags(t, func() { format, includeTotal, csvFields = tt.format, tt.total, nil })
+		out := captureOutput(t)
+		if err := printResults(results, true, structuredTotal(results), nil); err != nil {
+			t.Fatalf("printResults: %v", err)
+		}
+
+		switch tt.format {
+		case formatJSON:
+			shape, object := jsonShape(t, out.Bytes())
+			var total struct{ Files, Assignments, Branches, Conditions int }
+			if tt.total {
+				if shape != "object" || json.Unmarshal(object["total"], &total) != nil {
+					t.Fatalf("--include-total: got %s, want an object with a total", out)
+				}
+				if total.Files != 2 || total.Assignments != 3 || total.Branches != 4 || total.Conditions != 2 {
+					t.Errorf("got total %+v, want 2 files with A=3 B=4 C=2", total)
+				}
+			} else if shape != "array" {
+				t.Errorf("got %s, want an array without --include-total", shape)
+			}
+		case formatCSV:
+			// A header and a row per file, then the total row
+			want := 3
+			if tt.total {
+				want++
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			hasTotal := strings.HasPrefix(lines[len(lines)-1], "TOTAL,")
+			if len(lines) != want || hasTotal != tt.total {
+				t.Errorf("--include-total=%v: got CSV %q", tt.total, out)
+			}
+		}
+	}
+}
+
+func TestPluralFiles(t *testing.T) {
+	for count, want := range map[int]string{0: "0 files", 1: "1 file", 2: "2 files"} {
+		if got := pluralFiles(count); got != want {
+			t.Errorf("pluralFiles(%d) = %q, want %q", count, got, want)
+		}
+	}
+}
+
+func TestRunAnalysisSingleFileTotal(t *testing.T) {
+	files := []string{fixture("slices.go")}
+
+	setFlags(t, func() { format = formatText })
+	out := captureOutput(t)
+	if _, _, _, err := runAnalysis(files, true, nil); err != nil {
+		t.Fatalf("runAnalysis: %v", err)
+	}
+	if strings.Contains(out.String(), "Total") {
+		t.Errorf("got a total for a single file:\n%s", out)
+	}
+
+	setFlags(t, func() { summaryOnly = true })
+	out = captureOutput(t)
+	if _, _, _, err := runAnalysis(files, true, nil); err != nil {
+		t.Fatalf("runAnalysis: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Summary (1 file):") {
+		t.Errorf("got summary %q, want it to start with Summary (1 file):", out)
+	}
+}
+
+func TestRunTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		multiple bool
+		total    string // Value given to --include-total, "" when not given
+		set      func()
+		want     bool
+	}{
+		{"json single file", formatJSON, false, "", nil, false},
+		{"json single file with total", formatJSON, false, "true", nil, true},
+		{"json several files", formatJSON, true, "", nil, true},
+		{"json several files without total", formatJSON, true, "false", nil, false},
+		{"json several files as array", formatJSON, true, "", func() { jsonAlwaysArray = true }, false},
+		{"flattened details", formatJSON, true, "", func() { flattenDetails = true }, false},
+		{"csv several files", formatCSV, true, "", nil, true},
+		{"csv fields without path", formatCSV, true, "", func() { csvFields = []string{"score"} }, false},
+		{"jsonl several files", formatJSONL, true, "", nil, false},
+		{"text several files", formatText, true, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlags(t, func() {
+				format, includeTotal = tt.format, false
+				if tt.set != nil {
+					tt.set()
+				}
+			})
+			cmd := &cobra.Command{}
+			cmd.Flags().BoolVar(&includeTotal, "include-total", false, "")
+			cmd.Flags().BoolVar(&includeTotal, "with-totals", false, "")
+			if tt.total != "" {
+				if err := cmd.Flags().Set("include-total", tt.total); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := runTotal(cmd, tt.multiple); got != tt.want {
+				t.Errorf("runTotal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/go/packages"
+)
+
+func init() {
+	addCountFlags(analyzePkgCmd)
+	analyzePkgCmd.Flags().StringSliceVar(&buildTags, "tags", nil, "Build tags used when loading packages, as with go build -tags")
+	analyzePkgCmd.Flags().BoolVar(&includeGenerated, "include-generated", false, "Analyze generated Go files (with a \"Code generated ... DO NOT EDIT.\" header)")
+}
+
+// analyzePkgCmd represents the analyze-pkg command
+var analyzePkgCmd = &cobra.Command{
+	Use:   "analyze-pkg [pattern...]",
+	Short: "Analyze Go packages by import path or pattern",
+	Long: `Analyze Go packages given as import paths or patterns such as ./...
+(the default). Packages are resolved by the go command, so only the files
+that are part of the build for the current platform (or the one set by the
+GOOS and GOARCH environment variables) are analyzed, honoring build
+constraints and the build tags given by --tags, and test files are left out. Generated files are skipped
+unless --include-generated is set.
+
+Results are grouped by package, each with the combined metrics of its files,
+followed by a total when more than one package is analyzed. With --format
+json, the output is an array with one object per package; other structured
+formats list the files of all packages.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		if jobs < 1 {
+			logErrorf("--jobs must be at least 1, got %d", jobs)
+			os.Exit(1)
+		}
+
+		patterns := args
+		if len(patterns) == 0 {
+			patterns = []string{"./..."}
+		}
+
+		config := &packages.Config{Mode: packages.NeedName | packages.NeedFiles}
+		if len(buildTags) > 0 {
+			config.BuildFlags = []string{"-tags=" + strings.Join(buildTags, ",")}
+		}
+		pkgs, err := packages.Load(config, patterns...)
+		if err != nil {
+			logErrorf("error loading packages: %v", err)
+			os.Exit(1)
+		}
+		sort.Slice(pkgs, func(i, j int) bool {
+			return pkgs[i].PkgPath < pkgs[j].PkgPath
+		})
+
+		failed := false
+		var results []report.PackageResult
+		for _, pkg := range pkgs {
+			// Packages with errors are still analyzed as far as their files go
+			for _, pkgErr := range pkg.Errors {
+				logErrorf("loading %s: %v", pkg.PkgPath, pkgErr)
+				failed = true
+			}
+
+			result := report.PackageResult{Path: pkg.PkgPath}
+			for outcome := range analyzeFiles(packageFiles(pkg), jobs, nil) {
+				var timeoutErr *analyzer.TimeoutError
+				if errors.As(outcome.err, &timeoutErr) {
+					logWarnf("analysis of %s exceeded %s, skipping", outcome.path, analyzeTimeout)
+					continue
+				}
+				if outcome.err != nil {
+					logErrorf("analyzing %s: %v", outcome.path, outcome.err)
+					failed = true
+					continue
+				}
+				result.Files = append(result.Files, outcome.result)
+				printWarnings(outcome.path, outcome.result.Metrics)
+			}
+
+			// Packages that failed to load or only hold generated files have
+			// nothing to report
+			if len(result.Files) > 0 {
+				results = append(results, result)
+			}
+		}
+
+		closeOutput, err := openOutput()
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if err := printPackages(results); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		if err := closeOutput(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		// Only fail once every package has had its chance
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// packageFiles returns the Go files of the package to analyze, without
+// generated files unless --include-generated is set
+func packageFiles(pkg *packages.Package) []string {
+	var files []string
+	for _, file := range pkg.GoFiles {
+		if !includeGenerated && isGenerated(file) {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// printPackages prints the package results in the selected format
+func printPackages(results []report.PackageResult) error {
+	switch format {
+	case formatText:
+		// Printed below, grouped by package
+	case formatJSON:
+		return report.WritePackagesJSON(stdout, results, jsonOptions(), scoreWeights(), severityConfig())
+	default:
+		var files []report.FileResult
+		for _, result := range results {
+			files = append(files, result.Files...)
+		}
+		return printResults(files, true, structuredTotal(files), nil)
+	}
+
+	var all []metrics.ABCMetrics
+	fileCount := 0
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "Package: %s (%s)\n", result.Path, pluralFiles(len(result.Files)))
+		combined := result.Metrics()
+		printScore(combined)
+		for _, file := range result.Files {
+			fmt.Fprintf(stdout, "  %s: %s\n", file.Path, file.Metrics.StringWith(scoreWeights()))
+		}
+
+		all = append(all, combined)
+		fileCount += len(result.Files)
+	}
+
+	if len(results) > 1 {
+		printTotal(fileCount, metrics.CombineMetrics(all...))
+	}
+	return nil
+}
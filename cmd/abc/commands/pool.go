@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/abc-metrics/abc/internal/report"
+)
+
+// fileOutcome is the result of analyzing a single file in the worker pool
+type fileOutcome struct {
+	path   string
+	result report.FileResult
+	err    error
+}
+
+// analyzeFiles analyzes the files using up to jobs concurrent workers. Every
+// file gets an outcome, failures included, and the outcomes are delivered in
+// path order so the output does not depend on the order in which workers
+// finish. Each outcome is sent as soon as it and all outcomes before it are
+// done, so callers can print results while later files are still analyzed.
+// The channel is closed after the last outcome. Closing done tells the pool
+// that no more outcomes will be read: files not started yet are skipped and
+// the workers stop once their current file is done. A nil done reads every
+// outcome. A file whose analyzer panics fails like any other file instead
+// of aborting the run. With --verbose, progress is reported on stderr as
+// files complete.
+func analyzeFiles(files []string, jobs int, done <-chan struct{}) <-chan fileOutcome {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(files) {
+		jobs = len(files)
+	}
+
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	// Each worker writes only to its own slots, which are buffered so that
+	// workers never wait for the outcomes to be consumed
+	slots := make([]chan fileOutcome, len(sorted))
+	for i := range slots {
+		slots[i] = make(chan fileOutcome, 1)
+	}
+	indexes := make(chan int)
+	prog := newProgress(len(sorted))
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := analyzePathSafely(sorted[i])
+				slots[i] <- fileOutcome{path: sorted[i], result: result, err: err}
+				prog.fileDone(sorted[i])
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indexes)
+		for i := range sorted {
+			select {
+			case indexes <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	outcomes := make(chan fileOutcome)
+	go func() {
+		defer func() {
+			wg.Wait()
+			prog.finish()
+			close(outcomes)
+		}()
+		for _, slot := range slots {
+			var outcome fileOutcome
+			select {
+			case outcome = <-slot:
+			case <-done:
+				return
+			}
+			select {
+			case outcomes <- outcome:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return outcomes
+}
+
+// analyzePathSafely runs analyzePath, turning a panic of the analyzer into
+// an error of the file
+func analyzePathSafely(path string) (result report.FileResult, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("analyzer failed: %v", recovered)
+		}
+	}()
+	return analyzePath(path)
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// progressRefresh is the minimum time between updates of the live line
+	progressRefresh = 100 * time.Millisecond
+	// progressLogInterval is the minimum time between log lines when stderr
+	// is not a terminal
+	progressLogInterval = 5 * time.Second
+	// progressPathWidth is the number of characters of the current path shown
+	// on the live line
+	progressPathWidth = 60
+)
+
+// progress reports how many files of a scan have been analyzed on stderr.
+// On a terminal a single line is updated in place, unless results are
+// printed to the same terminal while the scan runs; otherwise a line is
+// logged every progressLogInterval. It is safe for concurrent use, and a nil
+// progress reports nothing.
+type progress struct {
+	mu       sync.Mutex
+	w        io.Writer
+	tty      bool
+	total    int
+	done     int
+	started  time.Time
+	reported time.Time
+}
+
+// newProgress returns a progress reporter for a scan of total files, or nil
+// unless --verbose is set and there is more than one file
+func newProgress(total int) *progress {
+	if !verbose || total < 2 {
+		return nil
+	}
+	now := time.Now()
+	tty := isTerminal(os.Stderr) && (outputPath != "" || !isTerminal(os.Stdout))
+	return &progress{w: os.Stderr, tty: tty, total: total, started: now, reported: now}
+}
+
+// fileDone records that the file has been analyzed
+func (p *progress) fileDone(path string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	now := time.Now()
+	if p.tty {
+		if p.done < p.total && now.Sub(p.reported) < progressRefresh {
+			return
+		}
+		fmt.Fprintf(p.w, "\r\033[KAnalyzed %d/%d files: %s", p.done, p.total, shortenPath(path, progressPathWidth))
+	} else {
+		if now.Sub(p.reported) < progressLogInterval {
+			return
+		}
+		logInfof("Analyzed %d/%d files (%s elapsed)", p.done, p.total, now.Sub(p.started).Round(time.Second))
+	}
+	p.reported = now
+}
+
+// finish clears the live line, so it does not mix with the output printed
+// after the scan
+func (p *progress) finish() {
+	if p == nil || !p.tty {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+// isTerminal reports whether the file is a character device such as a
+// terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// shortenPath keeps the last width characters of the path, marking the cut
+// with an ellipsis
+func shortenPath(path string, width int) string {
+	if len(path) <= width {
+		return path
+	}
+	return "..." + path[len(path)-width+3:]
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abc-metrics/abc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	rawFromPath string
+)
+
+func init() {
+	reportCmd.Flags().StringVar(&rawFromPath, "from", "", "Path to a raw results file written by 'analyze --save-raw'")
+	reportCmd.MarkFlagRequired("from")
+	reportCmd.MarkFlagFilename("from", "json")
+}
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Render previously saved analysis results",
+	Long: `Render a raw results file saved with 'analyze --save-raw' without
+re-parsing any source files. This separates the expensive analysis step
+from rendering, so one analysis run can be rendered many times.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		raw, err := report.ReadRaw(rawFromPath)
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		closeOutput, err := openOutput()
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		if err := printSavedResults(raw.Files); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		if err := closeOutput(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// printSavedResults prints results that were analyzed earlier, such as a raw
+// results file or merged reports, in the selected format. The text output is
+// the one of analyze, ending with the total when there are several files.
+func printSavedResults(results []report.FileResult) error {
+	for i, result := range results {
+		if format == formatText {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "Analyzing file: %s\n", result.Path)
+			printMetrics(result.Path, result.Metrics)
+			if len(result.Functions) > 0 {
+				printFunctions(result.Functions)
+			}
+		}
+		printWarnings(result.Path, result.Metrics)
+	}
+
+	if format == formatText && len(results) > 1 {
+		printTotal(len(results), report.NewTotal(results).Metrics)
+	}
+	return printResults(results, len(results) != 1, structuredTotal(results), nil)
+}
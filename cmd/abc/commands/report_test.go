@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintSavedResultsMatchesAnalyze(t *testing.T) {
+	setFlags(t, func() { format = formatText })
+	files := []string{fixture("slices.go"), fixture("guards.go")}
+
+	analyzed := captureOutput(t)
+	captureLog(t)
+	results, _, _, err := runAnalysis(files, true, nil)
+	if err != nil {
+		t.Fatalf("runAnalysis: %v", err)
+	}
+
+	saved := captureOutput(t)
+	if err := printSavedResults(results); err != nil {
+		t.Fatalf("printSavedResults: %v", err)
+	}
+	if !strings.Contains(analyzed.String(), "Total (2 files)") {
+		t.Errorf("analyze output has no total:\n%s", analyzed)
+	}
+	if saved.String() != analyzed.String() {
+		t.Errorf("report output differs from analyze output:\n%s\nwant:\n%s", saved, analyzed)
+	}
+}
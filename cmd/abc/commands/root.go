@@ -1,11 +1,11 @@
 package commands
 
 import (
-	"fmt"
 	"os"
+	"strings"
 
-	"github.com/abc-metrics/abc/internal/analyzer"
 	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +21,62 @@ based on the number of assignments, branches, and conditions in the code.
 The ABC score is calculated as sqrt(A² + B² + C²) where:
 - A: number of assignments
 - B: number of branches (function calls, method calls)
-- C: number of conditions (if, else, switch, case, for, while, etc.)`,
+- C: number of conditions (if, else, switch, case, for, while, etc.)
+
+The --weight-a, --weight-b and --weight-c flags scale each squared term,
+e.g. to weigh conditions more heavily than assignments.
+
+Defaults for some flags can be set in a .abc.yaml file in the current
+directory or in the file given by --config. Flags given on the command line
+always take precedence over the config file.`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if err := applyConfig(cmd); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			setupLogging()
+			allowUnsupportedSet = cmd.Flags().Changed("allow-unsupported")
+			if err := setupColor(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := validateSpec(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := validateDetailsFormat(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := report.ValidateCSVFields(csvFields); err != nil {
+				logErrorf("--fields: %v", err)
+				os.Exit(1)
+			}
+			if err := report.ValidateCSVTotal(csvFields, includeTotal && format == formatCSV); err != nil {
+				logErrorf("--include-total, --with-totals: %v", err)
+				os.Exit(1)
+			}
+			if err := validateFlattenDetails(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := validateJSONAlwaysArray(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := report.ValidateTheme(htmlTheme); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := severityConfig().Validate(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+			if err := scoreWeights().Validate(); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			// If no subcommand is provided, print help
 			cmd.Help()
@@ -32,68 +87,83 @@ The ABC score is calculated as sqrt(A² + B² + C²) where:
 	verbose     bool
 	filePath    string
 	showDetails bool
+	explain     bool
+	noScore     bool
+	noFormula   bool
+
+	jsonAlwaysArray bool
+	htmlTheme       string
+
+	includeSourceHash bool
+	flattenDetails    bool
+	summaryFooter     bool
+	format            string
+	configPath        string
+
+	collapseDetails bool
+	detailsFormat   string
+	outputPath      string
+	includeTotal    bool
+	csvFields       []string
+	colorMode       string
+	relativeTo      string
+
+	severityLow    float64
+	severityMedium float64
+	severityHigh   float64
+
+	weightA float64
+	weightB float64
+	weightC float64
 )
 
 func init() {
-	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	RootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to a config file with flag defaults (default .abc.yaml if present)")
+	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output and debug messages on stderr")
 	RootCmd.PersistentFlags().StringVarP(&filePath, "file", "f", "", "Path to the file for analysis")
 	RootCmd.PersistentFlags().BoolVar(&showDetails, "show", false, "Show detailed list of assignments, branches, and conditions")
+	RootCmd.PersistentFlags().BoolVar(&explain, "explain", false, "Summarize what drives each score in text output: the most assigned variables, the most called functions and the condition types")
+	RootCmd.PersistentFlags().BoolVar(&collapseDetails, "collapse", false, "With --show, print identical entries on the same line once with a count")
+	RootCmd.PersistentFlags().StringVar(&detailsFormat, "details-format", detailsPretty, "How --show prints detail lists in text output: pretty, compact (path:line:col: [A|B|C] text) or json (one object per line)")
+	RootCmd.PersistentFlags().StringVarP(&outputPath, "output", "o", "", "Write the results to this file instead of stdout")
+	RootCmd.PersistentFlags().BoolVar(&includeTotal, "include-total", false, "Add the combined total of all files to json output, which becomes an object with files and total, as a last line to jsonl output and as a last row to csv output (default on for json and csv output of several files)")
+	RootCmd.PersistentFlags().BoolVar(&includeTotal, "with-totals", false, "Same as --include-total, the spelling used by spreadsheet exports: add a TOTAL row to csv output")
+	RootCmd.PersistentFlags().StringSliceVar(&csvFields, "fields", nil, "Columns of csv output, in order: "+strings.Join(report.CSVFields, ", ")+" (default path, [function,] assignments, branches, conditions, score, severity)")
+	RootCmd.PersistentFlags().StringVar(&colorMode, "color", colorAuto, "Color severities and scores above --threshold in text output: auto (when stdout is a terminal and NO_COLOR is unset), always or never")
+	RootCmd.PersistentFlags().StringVar(&relativeTo, "relative-to", "", "Report file paths relative to this directory (default the working directory); paths outside it are absolute")
+	RootCmd.PersistentFlags().StringVar(&format, "format", formatText, "Output format: text, json, jsonl, csv, sarif, html, junit, markdown or table")
+	RootCmd.PersistentFlags().BoolVar(&noScore, "no-score", false, "Print only the A/B/C counts and severity, omitting the numeric score")
+	RootCmd.PersistentFlags().StringVar(&htmlTheme, "theme", report.ThemeLight, "Color theme of html output: light or dark")
+	RootCmd.PersistentFlags().BoolVar(&jsonAlwaysArray, "json-always-array", false, "Write json output for a single file as a one-element array instead of a bare object, and keep the array when files fail, reporting them on stderr only")
+	RootCmd.PersistentFlags().BoolVar(&includeSourceHash, "include-source-hash", false, "Add the SHA-256 of each analyzed file's content, the hash the result cache matches files by, to json and jsonl output as sourceHash")
+	RootCmd.PersistentFlags().BoolVar(&flattenDetails, "flatten-details", false, "Write json output as one flat array of {file, line, col, category, text, context} detail records instead of per-file objects")
+	RootCmd.PersistentFlags().BoolVar(&summaryFooter, "summary-footer", false, "End table output with a rule and a TOTAL row holding the combined metrics of every analyzed file, including those hidden by --min-score")
+	RootCmd.PersistentFlags().BoolVar(&noFormula, "no-formula", false, "Leave the formula field, the derivation of each score, out of json and jsonl output")
 
-	// Add the analyze command
-	RootCmd.AddCommand(analyzeCmd)
-}
+	RootCmd.MarkPersistentFlagFilename("config", "yaml", "yml")
+	RootCmd.MarkPersistentFlagFilename("file")
+	RootCmd.MarkPersistentFlagFilename("output")
+	RootCmd.MarkPersistentFlagDirname("relative-to")
+	completeValues(RootCmd, "format", outputFormats...)
+	completeValues(RootCmd, "details-format", detailsPretty, detailsCompact, detailsJSON)
+	completeValues(RootCmd, "color", colorAuto, colorAlways, colorNever)
+	completeValues(RootCmd, "fields", report.CSVFields...)
+	completeValues(RootCmd, "theme", report.HTMLThemes...)
 
-// analyzeCmd represents the analyze command
-var analyzeCmd = &cobra.Command{
-	Use:   "analyze",
-	Short: "Analyze a file for ABC metrics",
-	Long:  `Analyze a single file and calculate its ABC metrics.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if filePath == "" {
-			if len(args) == 0 {
-				fmt.Println("Error: file path is required")
-				cmd.Help()
-				os.Exit(1)
-			}
-			filePath = args[0]
-		}
-
-		fmt.Printf("Analyzing file: %s\n", filePath)
-
-		// Get analyzer for file
-		analyzer, err := analyzer.GetAnalyzerForFile(filePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Analyze file
-		abcMetrics, err := analyzer.AnalyzeFile(filePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error analyzing file: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Print results
-		fmt.Println(abcMetrics.String())
-		fmt.Printf("Complexity: %s\n", metrics.SeverityLevel(abcMetrics.Score()))
-
-		// If show details flag is set, print detailed metrics
-		if showDetails {
-			fmt.Println("\nAssignments:")
-			for i, assignment := range abcMetrics.AssignmentList {
-				fmt.Printf("  %d. Line %d: %s (%s)\n", i+1, assignment.Line, assignment.Text, assignment.Context)
-			}
+	RootCmd.PersistentFlags().Float64Var(&severityLow, "severity-low", metrics.DefaultSeverityConfig.Low, "Scores below this value are rated Low")
+	RootCmd.PersistentFlags().Float64Var(&severityMedium, "severity-medium", metrics.DefaultSeverityConfig.Medium, "Scores below this value are rated Medium")
+	RootCmd.PersistentFlags().Float64Var(&severityHigh, "severity-high", metrics.DefaultSeverityConfig.High, "Scores below this value are rated High, anything above Very High")
 
-			fmt.Println("\nBranches:")
-			for i, branch := range abcMetrics.BranchList {
-				fmt.Printf("  %d. Line %d: %s (%s)\n", i+1, branch.Line, branch.Text, branch.Context)
-			}
+	RootCmd.PersistentFlags().Float64Var(&weightA, "weight-a", metrics.DefaultWeights.A, "Weight of the assignment term in the score, sqrt(wA·A² + wB·B² + wC·C²)")
+	RootCmd.PersistentFlags().Float64Var(&weightB, "weight-b", metrics.DefaultWeights.B, "Weight of the branch term in the score")
+	RootCmd.PersistentFlags().Float64Var(&weightC, "weight-c", metrics.DefaultWeights.C, "Weight of the condition term in the score")
 
-			fmt.Println("\nConditions:")
-			for i, condition := range abcMetrics.ConditionList {
-				fmt.Printf("  %d. Line %d: %s (%s)\n", i+1, condition.Line, condition.Text, condition.Context)
-			}
-		}
-	},
+	// Add subcommands
+	RootCmd.AddCommand(analyzeCmd)
+	RootCmd.AddCommand(analyzePkgCmd)
+	RootCmd.AddCommand(reportCmd)
+	RootCmd.AddCommand(baselineCmd)
+	RootCmd.AddCommand(watchCmd)
+	RootCmd.AddCommand(compareCmd)
+	RootCmd.AddCommand(mergeCmd)
 }
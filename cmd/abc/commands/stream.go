@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// maxStreamLine is the longest request line accepted by the JSON stream
+const maxStreamLine = 64 * 1024 * 1024
+
+// streamRequest is a single line read by the JSON stream
+type streamRequest struct {
+	ID       json.RawMessage `json:"id"`       // Opaque request identifier echoed in the response
+	Lang     string          `json:"lang"`     // File extension without the dot, used when the filename has none
+	Filename string          `json:"filename"` // Name of the buffer, used for dispatch and positions
+	Source   string          `json:"source"`   // Source code to analyze
+}
+
+// streamResponse is a single line written by the JSON stream
+type streamResponse struct {
+	ID      json.RawMessage     `json:"id"`
+	Metrics *metrics.ABCMetrics `json:"metrics,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// runJSONStream reads one JSON request per line from in and writes one JSON
+// response per line to out until in is exhausted. Failures for a single
+// request are reported in its response and do not stop the stream.
+func runJSONStream(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req streamRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := encoder.Encode(streamResponse{Error: fmt.Sprintf("invalid request: %v", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		resp := streamResponse{ID: req.ID}
+		abcMetrics, err := analyzeStreamRequest(req)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Metrics = &abcMetrics
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// analyzeStreamRequest analyzes the source of a single stream request
+func analyzeStreamRequest(req streamRequest) (metrics.ABCMetrics, error) {
+	// Dispatch on the filename, falling back to the language as extension
+	dispatchPath := req.Filename
+	if filepath.Ext(dispatchPath) == "" && req.Lang != "" {
+		dispatchPath = "buffer." + req.Lang
+	}
+
+	fileAnalyzer, err := analyzer.GetAnalyzerForFileWithConfig(dispatchPath, countConfig())
+	if err != nil {
+		return metrics.ABCMetrics{}, err
+	}
+
+	ctx := context.Background()
+	if analyzeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, analyzeTimeout)
+		defer cancel()
+	}
+
+	return analyzer.AnalyzeSourceContext(ctx, fileAnalyzer, req.Filename, []byte(req.Source))
+}
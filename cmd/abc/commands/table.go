@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
+)
+
+// tableRow is one row of the table output, its cells already formatted
+type tableRow struct {
+	cells    []string
+	score    float64
+	severity string
+}
+
+// printTable prints the results as an aligned table with one row per file:
+// the path, the counts and the score right-aligned, and the severity, colored
+// like the text output. With a total, a rule and a footer row with the
+// combined metrics follow.
+func printTable(results []report.FileResult, total *report.Total) {
+	header := []string{"Path", "A", "B", "C", "Score", "Severity"}
+	if noScore {
+		header = []string{"Path", "A", "B", "C", "Severity"}
+	}
+
+	var rows []tableRow
+	for _, result := range results {
+		rows = append(rows, newTableRow(result.Path, result.Metrics))
+	}
+	var footer *tableRow
+	if total != nil {
+		row := newTableRow(fmt.Sprintf("TOTAL (%d files)", total.Files), total.Metrics)
+		footer = &row
+	}
+
+	// Widths are measured before coloring, which adds invisible characters
+	widths := make([]int, len(header))
+	for i, cell := range header {
+		widths[i] = len(cell)
+	}
+	for _, row := range append(rows, footerRows(footer)...) {
+		for i, cell := range row.cells {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	last := len(header) - 1
+	fmt.Fprintln(stdout, formatTableRow(header[:last], widths[:last])+"  "+header[last])
+	for _, row := range rows {
+		fmt.Fprintln(stdout, formatColoredRow(row, widths))
+	}
+	if footer != nil {
+		rule := 0
+		for _, width := range widths {
+			rule += width
+		}
+		fmt.Fprintln(stdout, strings.Repeat("-", rule+2*(len(widths)-1)))
+		fmt.Fprintln(stdout, formatColoredRow(*footer, widths))
+	}
+}
+
+// newTableRow formats the cells of a row of the table output
+func newTableRow(path string, m metrics.ABCMetrics) tableRow {
+	row := tableRow{
+		score:    score(m),
+		severity: metrics.SeverityLevelWith(score(m), severityConfig()),
+	}
+	row.cells = []string{path, strconv.Itoa(m.Assignments), strconv.Itoa(m.Branches), strconv.Itoa(m.Conditions)}
+	if !noScore {
+		row.cells = append(row.cells, fmt.Sprintf("%.2f", row.score))
+	}
+	row.cells = append(row.cells, row.severity)
+	return row
+}
+
+// footerRows returns the footer as a list of zero or one rows
+func footerRows(footer *tableRow) []tableRow {
+	if footer == nil {
+		return nil
+	}
+	return []tableRow{*footer}
+}
+
+// formatTableRow pads the cells to the column widths, the path left-aligned
+// and the numbers after it right-aligned. The severity column is left out and
+// appended by the caller, as it is last and needs no padding.
+func formatTableRow(cells []string, widths []int) string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		if i == 0 {
+			padded[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		} else {
+			padded[i] = fmt.Sprintf("%*s", widths[i], cell)
+		}
+	}
+	return strings.Join(padded, "  ")
+}
+
+// formatColoredRow formats a row with formatTableRow, coloring the path and
+// numbers when the score is above --threshold and the severity by its level
+func formatColoredRow(row tableRow, widths []int) string {
+	line := formatTableRow(row.cells[:len(row.cells)-1], widths[:len(widths)-1])
+	return colorScore(line, row.score) + "  " + colorSeverity(row.severity)
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+	"github.com/abc-metrics/abc/internal/report"
+)
+
+// exitThreshold is the exit code used when a score exceeds --threshold,
+// reaches --fail-on-severity or grows past --baseline
+const exitThreshold = 2
+
+// thresholdBreaches lists every file, function and, when total is non-nil,
+// the combined total whose score exceeds the threshold
+func thresholdBreaches(results []report.FileResult, total *metrics.ABCMetrics, threshold float64) []string {
+	var breaches []string
+	for _, result := range results {
+		if score := score(result.Metrics); score > threshold {
+			breaches = append(breaches, fmt.Sprintf("%s (%.2f)", result.Path, score))
+		}
+		for _, fn := range result.Functions {
+			if score := score(fn.Metrics); score > threshold {
+				breaches = append(breaches, functionBreach(result.Path, fn, score))
+			}
+		}
+	}
+
+	if total != nil {
+		if score := score(*total); score > threshold {
+			breaches = append(breaches, fmt.Sprintf("total (%.2f)", score))
+		}
+	}
+
+	return breaches
+}
+
+// printBreaches reports threshold breaches on stderr
+func printBreaches(breaches []string, threshold float64) {
+	fmt.Fprintf(os.Stderr, "ABC score threshold %.2f exceeded by:\n", threshold)
+	for _, breach := range breaches {
+		fmt.Fprintf(os.Stderr, "  %s\n", breach)
+	}
+}
+
+// functionBreaches lists every function whose score exceeds the threshold
+func functionBreaches(results []report.FileResult, threshold float64) []string {
+	var breaches []string
+	for _, result := range results {
+		for _, fn := range result.Functions {
+			if score := score(fn.Metrics); score > threshold {
+				breaches = append(breaches, functionBreach(result.Path, fn, score))
+			}
+		}
+	}
+	return breaches
+}
+
+// functionBreach formats a function breach as path:line name (score), so
+// editors and terminals can jump to the start of the function
+func functionBreach(path string, fn metrics.FunctionMetrics, score float64) string {
+	return fmt.Sprintf("%s:%d %s (%.2f)", path, fn.StartLine, fn.Name, score)
+}
+
+// printFunctionBreaches reports function threshold breaches on stderr
+func printFunctionBreaches(breaches []string, threshold float64) {
+	fmt.Fprintf(os.Stderr, "Function ABC score threshold %.2f exceeded by:\n", threshold)
+	for _, breach := range breaches {
+		fmt.Fprintf(os.Stderr, "  %s\n", breach)
+	}
+}
+
+// severityBreaches lists every file rated at the given severity level or
+// above
+func severityBreaches(results []report.FileResult, minSeverity string) []string {
+	var breaches []string
+	for _, result := range results {
+		score := score(result.Metrics)
+		if level := metrics.SeverityLevelWith(score, severityConfig()); metrics.SeverityAtLeast(level, minSeverity) {
+			breaches = append(breaches, fmt.Sprintf("%s (%.2f, %s)", result.Path, score, level))
+		}
+	}
+	return breaches
+}
+
+// printSeverityBreaches reports files at or above the severity level on
+// stderr
+func printSeverityBreaches(breaches []string, minSeverity string) {
+	fmt.Fprintf(os.Stderr, "Files rated %s or above:\n", minSeverity)
+	for _, breach := range breaches {
+		fmt.Fprintf(os.Stderr, "  %s\n", breach)
+	}
+}
+
+// printFailures lists the files that could not be analyzed on stderr
+func printFailures(failures []report.Failure) {
+	fmt.Fprintf(os.Stderr, "%s failed:\n", pluralFiles(len(failures)))
+	for _, failure := range failures {
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", failure.Path, failure.Error)
+	}
+}
+
+// printRegressions reports files whose score grew past the baseline on stderr
+func printRegressions(regressions []report.Regression, baselinePath string) {
+	fmt.Fprintf(os.Stderr, "ABC score regressions against baseline %s:\n", baselinePath)
+	for _, r := range regressions {
+		if r.New {
+			fmt.Fprintf(os.Stderr, "  %s (%.2f, not in baseline)\n", r.Path, r.Score)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  %s (%.2f, was %.2f)\n", r.Path, r.Score, r.Baseline)
+	}
+}
@@ -0,0 +1,298 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+)
+
+// expandPaths turns the given paths into the list of files to analyze. Each
+// path is resolved on its own, in this order: an existing directory is
+// walked recursively, any other existing path is a file, and a path that
+// does not exist is expanded as a glob pattern if it contains glob
+// metacharacters, or else kept as a file whose analysis will fail. A file
+// reached through several paths, such as a file inside a directory that is
+// also given, is only listed once. It reports whether more than one file,
+// any directory or any pattern was requested, and whether walking a
+// directory failed or a pattern matched nothing; such failures are reported
+// on stderr without stopping the other paths. Files named explicitly that no
+// analyzer supports are dropped with a warning when --allow-unsupported is
+// in effect, by default whenever more than one file may be analyzed. Finding
+// more than --max-files candidate files stops the expansion with an error
+// instead, before any file is analyzed.
+func expandPaths(paths []string) (files []string, multiple, failed bool, err error) {
+	multiple = len(paths) > 1
+	for _, path := range paths {
+		if maxFiles > 0 && len(files) > maxFiles {
+			break
+		}
+
+		info, err := os.Stat(path)
+		if err != nil && isGlob(path) {
+			multiple = true
+			matches, err := expandGlob(path, fileBudget(files))
+			if errors.Is(err, errTooManyFiles) {
+				return nil, multiple, failed, tooManyFiles()
+			}
+			if err != nil {
+				logErrorf("%v", err)
+				failed = true
+			} else if len(matches) == 0 {
+				logErrorf("no files matched %s", path)
+				failed = true
+			}
+			files = append(files, matches...)
+			continue
+		}
+		if err != nil || !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		multiple = true
+		dirFiles, err := collectFiles(path, fileBudget(files))
+		if errors.Is(err, errTooManyFiles) {
+			return nil, multiple, failed, tooManyFiles()
+		}
+		if err != nil {
+			logErrorf("%v", err)
+			failed = true
+		}
+		files = append(files, dirFiles...)
+	}
+
+	if allowUnsupported || multiple && !allowUnsupportedSet {
+		files = supportedFiles(files)
+	}
+	files = uniqueFiles(files)
+	if maxFiles > 0 && len(files) > maxFiles {
+		return nil, multiple, failed, tooManyFiles()
+	}
+	return files, multiple, failed, nil
+}
+
+// errTooManyFiles stops a walk that found more candidate files than its
+// budget allows
+var errTooManyFiles = errors.New("too many files")
+
+// fileBudget returns how many more files may be found after the files listed
+// so far before --max-files is exceeded, or -1 when there is no limit
+func fileBudget(files []string) int {
+	if maxFiles <= 0 {
+		return -1
+	}
+	return max(maxFiles-len(files), 0)
+}
+
+// tooManyFiles returns the error reported when the paths expand to more than
+// --max-files files
+func tooManyFiles() error {
+	return fmt.Errorf("more than %d files to analyze; narrow the paths or raise --max-files (0 disables the limit)", maxFiles)
+}
+
+// allowUnsupportedSet records whether --allow-unsupported was given
+// explicitly, overriding its default that depends on the number of files
+var allowUnsupportedSet bool
+
+// supportedFiles drops the files no analyzer supports, with a warning
+func supportedFiles(files []string) []string {
+	supported := files[:0]
+	for _, file := range files {
+		_, err := analyzer.GetAnalyzerForFile(file)
+		var unsupportedErr *analyzer.UnsupportedFileError
+		if errors.As(err, &unsupportedErr) {
+			logWarnf("skipping %s: unsupported file type", file)
+			continue
+		}
+		supported = append(supported, file)
+	}
+	return supported
+}
+
+// uniqueFiles drops every file already listed under the same absolute path,
+// keeping the first occurrence
+func uniqueFiles(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	unique := files[:0]
+	for _, file := range files {
+		key, err := filepath.Abs(file)
+		if err != nil {
+			key = filepath.Clean(file)
+		}
+		if seen[key] {
+			logDebugf("skipping %s: already listed", file)
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, file)
+	}
+	return unique
+}
+
+// collectFiles walks the directory tree rooted at root and returns every file
+// that one of the analyzers supports, in lexical order. Unsupported files are
+// skipped silently, as are files and directories matching an ignore rule
+// and, unless --include-generated is set, generated Go files. Ignored
+// directories are not descended into. Symbolic links to directories
+// are not followed, so links pointing back up the tree cannot cause infinite
+// loops. The walk stops with errTooManyFiles as soon as more than limit files
+// are found, unless limit is negative.
+func collectFiles(root string, limit int) ([]string, error) {
+	rules, err := walkIgnoreRules(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && rules.ignored(relativePath(root, path), d.IsDir()) {
+			logDebugf("skipping %s: matches an ignore rule", path)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		keep, err := analyzable(path, d)
+		if !keep || err != nil {
+			return err
+		}
+
+		files = append(files, path)
+		if limit >= 0 && len(files) > limit {
+			return errTooManyFiles
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// analyzable reports whether a file found while walking a directory should
+// be analyzed. Symbolic links to directories, files no analyzer supports,
+// generated Go files unless --include-generated is set and files excluded by
+// build constraints are skipped, with a debug message.
+func analyzable(path string, d fs.DirEntry) (bool, error) {
+	// WalkDir never descends into symlinked directories, but a link to a
+	// directory still shows up as an entry that must be skipped
+	if d.Type()&fs.ModeSymlink != 0 {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return false, nil
+		}
+	}
+
+	_, err := analyzer.GetAnalyzerForFile(path)
+	var unsupportedErr *analyzer.UnsupportedFileError
+	if errors.As(err, &unsupportedErr) {
+		logDebugf("skipping %s: unsupported file type", path)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !includeGenerated && isGenerated(path) {
+		logDebugf("skipping %s: generated file", path)
+		return false, nil
+	}
+	if !matchesBuild(path) {
+		logDebugf("skipping %s: excluded by build constraints", path)
+		return false, nil
+	}
+	return true, nil
+}
+
+// relativePath returns path relative to root with forward slashes, the form
+// ignore rules are matched against
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// isGlob reports whether the path contains glob metacharacters
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// expandGlob returns the supported files matching a glob pattern, in lexical
+// order. Patterns use forward slashes on every platform and follow the
+// syntax of ignore rules: "*", "?" and character classes match within a
+// path segment and a "**" segment matches any number of directories. The
+// directory before the first segment with a metacharacter is walked, without
+// following symbolic links to directories. Matches are filtered like the
+// files of collectFiles, with the ignore rules of the walked directory. Like
+// collectFiles, it stops with errTooManyFiles once more than limit files
+// match, unless limit is negative.
+func expandGlob(pattern string, limit int) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	static := 0
+	for static < len(segments)-1 && !isGlob(segments[static]) {
+		static++
+	}
+
+	root := strings.Join(segments[:static], "/")
+	switch {
+	case static == 0:
+		root = "."
+	case root == "":
+		root = "/"
+	}
+	if _, err := path.Match(strings.Join(segments[static:], "/"), ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+
+	walkRoot := filepath.FromSlash(root)
+	rules, err := walkIgnoreRules(walkRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.WalkDir(walkRoot, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A pattern whose base directory does not exist matches nothing
+			if file == walkRoot && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		rel := relativePath(walkRoot, file)
+		if file != walkRoot && rules.ignored(rel, d.IsDir()) {
+			logDebugf("skipping %s: matches an ignore rule", file)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !matchSegments(segments[static:], strings.Split(rel, "/")) {
+			return nil
+		}
+
+		keep, err := analyzable(file, d)
+		if !keep || err != nil {
+			return err
+		}
+		files = append(files, file)
+		if limit >= 0 && len(files) > limit {
+			return errTooManyFiles
+		}
+		return nil
+	})
+	return files, err
+}
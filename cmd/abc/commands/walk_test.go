@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fixture returns the path of a file or directory under test-files
+func fixture(name string) string {
+	return filepath.Join("..", "..", "..", "test-files", name)
+}
+
+func TestCollectFilesWalksDirectoryNamedLikeFile(t *testing.T) {
+	dir := fixture("directory.py")
+	files, err := collectFiles(dir, -1)
+	if err != nil {
+		t.Fatalf("collectFiles(%s): %v", dir, err)
+	}
+	if want := []string{filepath.Join(dir, "inner.py")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("got %v, want %v", files, want)
+	}
+
+	if _, err := collectFiles(dir, 0); !errors.Is(err, errTooManyFiles) {
+		t.Errorf("limit 0: got error %v, want %v", err, errTooManyFiles)
+	}
+}
+
+func TestExpandPathsDirectory(t *testing.T) {
+	dir := fixture("directory.py")
+	inner := filepath.Join(dir, "inner.py")
+	tests := []struct {
+		name     string
+		paths    []string
+		want     []string
+		multiple bool
+	}{
+		{"directory", []string{dir}, []string{inner}, true},
+		{"file", []string{inner}, []string{inner}, false},
+		{"file inside a directory also given", []string{dir, inner}, []string{inner}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, multiple, failed, err := expandPaths(tt.paths)
+			if err != nil {
+				t.Fatalf("expandPaths(%v): %v", tt.paths, err)
+			}
+			if !reflect.DeepEqual(files, tt.want) || multiple != tt.multiple || failed {
+				t.Errorf("got %v, multiple=%v, failed=%v; want %v, multiple=%v, failed=false",
+					files, multiple, failed, tt.want, tt.multiple)
+			}
+		})
+	}
+}
+
+func TestExpandPathsMixedArguments(t *testing.T) {
+	dir := fixture("directory.py")
+	inner := filepath.Join(dir, "inner.py")
+	goFile := fixture("test.go")
+	cFile := filepath.Join(fixture("c"), "counting.c")
+
+	tests := []struct {
+		name   string
+		paths  []string
+		want   []string
+		failed bool
+	}{
+		{"file, directory and pattern", []string{goFile, dir, fixture("c/*.c")}, []string{goFile, inner, cFile}, false},
+		{"pattern overlapping a file", []string{fixture("c/counting.*"), cFile}, []string{cFile, filepath.Join(fixture("c"), "counting.cpp")}, false},
+		{"pattern matching nothing", []string{goFile, fixture("c/*.rs")}, []string{goFile}, true},
+		{"missing file", []string{fixture("missing.go"), dir}, []string{fixture("missing.go"), inner}, false},
+		{"unsupported file", []string{goFile, filepath.Join("..", "..", "..", "LICENSE")}, []string{goFile}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureLog(t)
+			files, multiple, failed, err := expandPaths(tt.paths)
+			if err != nil {
+				t.Fatalf("expandPaths(%v): %v", tt.paths, err)
+			}
+			if !reflect.DeepEqual(files, tt.want) || !multiple || failed != tt.failed {
+				t.Errorf("got %v, multiple=%v, failed=%v; want %v, multiple=true, failed=%v",
+					files, multiple, failed, tt.want, tt.failed)
+			}
+		})
+	}
+}
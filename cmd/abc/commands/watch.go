@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/abc-metrics/abc/internal/analyzer"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	watchDebounce time.Duration
+)
+
+func init() {
+	addAnalysisFlags(watchCmd)
+	watchCmd.Flags().BoolVar(&byFunction, "by-function", false, "Break metrics down per function, sorted by descending score")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 300*time.Millisecond, "Wait this long after the last change before re-analyzing")
+}
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [path...]",
+	Short: "Re-analyze files or directories whenever they change",
+	Long: `Analyze files or directories like 'analyze', then keep watching them and
+print updated metrics every time a supported file is written, created,
+removed or renamed. Changes arriving in quick succession, such as an editor
+saving several files at once, are batched into a single run (see --debounce).
+
+Directories are watched recursively, including subdirectories created
+later, and the same ignore rules as 'analyze' apply. With --output, the file
+is rewritten with the latest results on every run. Press Ctrl-C to stop.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(); err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+
+		paths := args
+		if filePath != "" {
+			paths = append([]string{filePath}, args...)
+		}
+		if len(paths) == 0 {
+			logErrorf("file path is required")
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		if jobs < 1 {
+			logErrorf("--jobs must be at least 1, got %d", jobs)
+			os.Exit(1)
+		}
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			logErrorf("%v", err)
+			os.Exit(1)
+		}
+		defer watcher.Close()
+
+		targets := newWatchTargets(watcher)
+		for _, path := range paths {
+			if err := targets.add(path); err != nil {
+				logErrorf("%v", err)
+				os.Exit(1)
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		reanalyze(paths)
+		logInfof("Watching %d path(s) for changes, press Ctrl-C to stop...", len(paths))
+
+		// The timer is created stopped and restarted on every relevant event,
+		// so a burst of events results in a single run
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logInfof("Stopped watching")
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if targets.relevant(event) {
+					debounce.Reset(watchDebounce)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logWarnf("%v", err)
+
+			case <-debounce.C:
+				logInfof("\nChange detected at %s, re-analyzing...", time.Now().Format(time.TimeOnly))
+				reanalyze(paths)
+			}
+		}
+	},
+}
+
+// reanalyze runs a full analysis of the paths and prints the results. Errors
+// are reported on stderr without stopping the watch.
+func reanalyze(paths []string) {
+	// Directories are walked again on every run to pick up new files
+	files, multiple, _, err := expandPaths(paths)
+	if err != nil {
+		logErrorf("%v", err)
+		return
+	}
+
+	closeOutput, err := openOutput()
+	if err != nil {
+		logErrorf("%v", err)
+		return
+	}
+	if _, _, _, err := runAnalysis(files, multiple, nil); err != nil {
+		logErrorf("%v", err)
+	}
+	if err := closeOutput(); err != nil {
+		logErrorf("%v", err)
+	}
+}
+
+// watchTargets tracks what is being watched. Files are watched through their
+// parent directory, because many editors save by replacing the file, which
+// would silently end a watch on the file itself.
+type watchTargets struct {
+	watcher *fsnotify.Watcher
+	files   map[string]bool // Files given explicitly
+	dirs    map[string]bool // Directories watched recursively
+}
+
+// newWatchTargets returns an empty set of targets for the watcher
+func newWatchTargets(watcher *fsnotify.Watcher) *watchTargets {
+	return &watchTargets{watcher: watcher, files: map[string]bool{}, dirs: map[string]bool{}}
+}
+
+// add starts watching a file, or a directory and all of its subdirectories
+// not excluded by the ignore rules
+func (t *watchTargets) add(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		t.files[filepath.Clean(path)] = true
+		return t.watcher.Add(filepath.Dir(path))
+	}
+
+	rules, err := walkIgnoreRules(path)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(path, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if dir != path && rules.ignored(relativePath(path, dir), true) {
+			return filepath.SkipDir
+		}
+		if err := t.watcher.Add(dir); err != nil {
+			return err
+		}
+		t.dirs[filepath.Clean(dir)] = true
+		return nil
+	})
+}
+
+// relevant reports whether the event should trigger a new analysis. New
+// subdirectories of watched directories are added to the watch on the way.
+func (t *watchTargets) relevant(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	name := filepath.Clean(event.Name)
+	if t.files[name] {
+		return true
+	}
+	if !t.dirs[filepath.Dir(name)] {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			if err := t.add(name); err != nil {
+				logWarnf("%v", err)
+			}
+			return true
+		}
+	}
+
+	_, err := analyzer.GetAnalyzerForFile(name)
+	return err == nil
+}
@@ -1,12 +1,20 @@
 module github.com/abc-metrics/abc
 
-go 1.22
+go 1.22.0
 
 toolchain go1.23.11
 
-require github.com/spf13/cobra v1.9.1
+require (
+	github.com/fsnotify/fsnotify v1.8.0
+	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
+	golang.org/x/tools v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
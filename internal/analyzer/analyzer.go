@@ -1,26 +1,122 @@
 package analyzer
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/abc-metrics/abc/internal/metrics"
 )
 
+// Version identifies the counting rules of the analyzers. It must be bumped
+// whenever a change alters the metrics computed for some source, so results
+// cached by earlier versions are not reused. Detail texts count too: version
+// 6 names Go assignment targets the way version 5 names branch receivers,
+// such as configs[...].Server.Timeout. Version 7 counts the blank key or
+// value of a range loop with CountRangeAssignments, like any other blank
+// target. Version 8 gives every target of a Python assignment its own detail.
+const Version = 8
+
 // Analyzer defines the interface for language-specific analyzers
 type Analyzer interface {
 	// AnalyzeFile analyzes a single file and returns ABC metrics
 	AnalyzeFile(filePath string) (metrics.ABCMetrics, error)
 
+	// AnalyzeSource analyzes source code already loaded in memory and returns
+	// ABC metrics. The filename is used for position information only.
+	AnalyzeSource(filename string, src []byte) (metrics.ABCMetrics, error)
+
 	// SupportedExtensions returns a list of file extensions supported by this analyzer
 	SupportedExtensions() []string
 }
 
+// FunctionAnalyzer is implemented by analyzers that can break the metrics of
+// a file down per function
+type FunctionAnalyzer interface {
+	// AnalyzeFileByFunction analyzes a single file and returns ABC metrics for
+	// each function in it
+	AnalyzeFileByFunction(filePath string) ([]metrics.FunctionMetrics, error)
+
+	// AnalyzeSourceByFunction analyzes source code already loaded in memory
+	// and returns ABC metrics for each function in it. The filename is used
+	// for position information only.
+	AnalyzeSourceByFunction(filename string, src []byte) ([]metrics.FunctionMetrics, error)
+}
+
+// CountConfig holds optional counting rules, the single place where what
+// counts can be tuned. The zero value keeps the default counting behavior:
+// Count* fields add items that are not counted by default and Skip* fields
+// leave out items that are. Rules only apply to Go files: the TypeScript,
+// Python and C analyzers take no configuration and always count with the
+// default rules.
+type CountConfig struct {
+	// CountRangeAssignments counts the key/value variables bound by a
+	// range loop as assignments
+	CountRangeAssignments bool
+
+	// CountGuards tallies if statements whose body is a single return
+	// statement. The tally is reported separately and does not change counts.
+	CountGuards bool
+
+	// LintGoroutines reports functions that launch goroutines without any
+	// visible way to join them. It only adds warnings and never affects counts.
+	LintGoroutines bool
+
+	// CountReturns counts every return statement as a branch, as in the
+	// classic definition of the metric
+	CountReturns bool
+
+	// CountLiteralElements counts every element of a composite literal, such
+	// as the key/value pairs of a map literal, as an assignment
+	CountLiteralElements bool
+
+	// SkipInitAssignments leaves out assignments made by the init statements
+	// of if, switch and for statements and the post statements of for
+	// loops, such as v, ok := m[k] in if v, ok := m[k]; ok
+	SkipInitAssignments bool
+
+	// SkipBlankAssignments leaves out assignments to the blank identifier _,
+	// such as the ok of v, _ := m[k], in every form of assignment
+	SkipBlankAssignments bool
+
+	// SkipDeferredCalls leaves out the calls of defer statements, which
+	// run on every exit path rather than adding one. Calls inside a deferred
+	// closure still count.
+	SkipDeferredCalls bool
+
+	// SkipTypeAssertions leaves out type assertions such as x.(T), which
+	// are conditions by default
+	SkipTypeAssertions bool
+
+	// CountComparisons counts every comparison operator (==, !=, <, <=, >,
+	// >=) as a condition, in addition to the statement it appears in
+	CountComparisons bool
+
+	// TrackNesting records the nesting depth of every condition in its
+	// context and the deepest one in ABCMetrics.MaxNesting. It never affects
+	// counts.
+	TrackNesting bool
+}
+
 // GetAnalyzerForFile returns the appropriate analyzer for the given file path
 // based on the file extension
 func GetAnalyzerForFile(filePath string) (Analyzer, error) {
+	return GetAnalyzerForFileWithConfig(filePath, CountConfig{})
+}
+
+// GetAnalyzerForFileWithConfig returns the appropriate analyzer for the given
+// file path, configured with the given counting rules
+func GetAnalyzerForFileWithConfig(filePath string, cfg CountConfig) (Analyzer, error) {
 	// Initialize available analyzers
 	analyzers := []Analyzer{
-		NewGoAnalyzer(),
-		// Add more analyzers as they are implemented
-		// NewTypeScriptAnalyzer(),
+		NewGoAnalyzerWithConfig(cfg),
+		NewTypeScriptAnalyzer(),
+		NewPythonAnalyzer(),
+		NewCAnalyzer(),
 	}
 
 	// Find the first analyzer that supports the file extension
@@ -35,12 +131,157 @@ func GetAnalyzerForFile(filePath string) (Analyzer, error) {
 	return nil, &UnsupportedFileError{FilePath: filePath}
 }
 
-// HasExtension checks if a file path has the given extension
+// AnalyzeSourceContext runs AnalyzeSource in a separate goroutine and stops
+// waiting for it once ctx is done. The abandoned analysis keeps running in the
+// background until it finishes, but the caller is no longer blocked by it.
+func AnalyzeSourceContext(ctx context.Context, a Analyzer, filename string, src []byte) (metrics.ABCMetrics, error) {
+	type result struct {
+		metrics metrics.ABCMetrics
+		err     error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		defer func() {
+			r.err = recoverPanic(recover(), r.err)
+			done <- r
+		}()
+		r.metrics, r.err = a.AnalyzeSource(filename, src)
+	}()
+
+	select {
+	case r := <-done:
+		return r.metrics, r.err
+	case <-ctx.Done():
+		return metrics.ABCMetrics{}, &TimeoutError{FilePath: filename}
+	}
+}
+
+// AnalyzeFileWithTimeout reads and analyzes a file, giving up once the timeout
+// elapses. A zero timeout means no limit.
+func AnalyzeFileWithTimeout(a Analyzer, filePath string, timeout time.Duration) (metrics.ABCMetrics, error) {
+	if timeout <= 0 {
+		return a.AnalyzeFile(filePath)
+	}
+
+	content, err := ReadSource(filePath)
+	if err != nil {
+		return metrics.ABCMetrics{}, err
+	}
+	return AnalyzeSourceWithTimeout(a, filePath, content, timeout)
+}
+
+// AnalyzeSourceWithTimeout analyzes source code already loaded in memory,
+// giving up once the timeout elapses. A zero timeout means no limit.
+func AnalyzeSourceWithTimeout(a Analyzer, filename string, src []byte, timeout time.Duration) (metrics.ABCMetrics, error) {
+	if timeout <= 0 {
+		return a.AnalyzeSource(filename, src)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return AnalyzeSourceContext(ctx, a, filename, src)
+}
+
+// AnalyzeFileByFunctionWithTimeout reads a file and breaks its metrics down
+// per function, giving up once the timeout elapses. A zero timeout means no
+// limit.
+func AnalyzeFileByFunctionWithTimeout(a FunctionAnalyzer, filePath string, timeout time.Duration) ([]metrics.FunctionMetrics, error) {
+	if timeout <= 0 {
+		return a.AnalyzeFileByFunction(filePath)
+	}
+
+	content, err := ReadSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeSourceByFunctionWithTimeout(a, filePath, content, timeout)
+}
+
+// AnalyzeSourceByFunctionWithTimeout runs AnalyzeSourceByFunction, giving up
+// once the timeout elapses. A zero timeout means no limit. Like
+// AnalyzeSourceContext, an abandoned analysis keeps running in the background.
+func AnalyzeSourceByFunctionWithTimeout(a FunctionAnalyzer, filename string, src []byte, timeout time.Duration) ([]metrics.FunctionMetrics, error) {
+	if timeout <= 0 {
+		return a.AnalyzeSourceByFunction(filename, src)
+	}
+
+	type result struct {
+		functions []metrics.FunctionMetrics
+		err       error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		defer func() {
+			r.err = recoverPanic(recover(), r.err)
+			done <- r
+		}()
+		r.functions, r.err = a.AnalyzeSourceByFunction(filename, src)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.functions, r.err
+	case <-timer.C:
+		return nil, &TimeoutError{FilePath: filename}
+	}
+}
+
+// recoverPanic turns the value recovered from a panicking analyzer into an
+// error, so a malformed file fails on its own instead of aborting the run. It
+// returns err when nothing was recovered.
+func recoverPanic(recovered any, err error) error {
+	if recovered == nil {
+		return err
+	}
+	return fmt.Errorf("analyzer failed: %v", recovered)
+}
+
+// countLines returns the number of lines in the source, counting a final
+// line without a trailing newline
+func countLines(src []byte) int {
+	lines := bytes.Count(src, []byte("\n"))
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// HasExtension checks if a file path has the given extension. The comparison
+// is case-insensitive and the extension must follow a "." in the file name,
+// so "main.GO" has the extension ".go" (or "go") but "foogo" and ".go" do not.
 func HasExtension(filePath, extension string) bool {
-	if len(filePath) < len(extension) {
+	extension = "." + strings.TrimPrefix(extension, ".")
+	name := filepath.Base(filePath)
+	if len(name) <= len(extension) {
 		return false
 	}
-	return filePath[len(filePath)-len(extension):] == extension
+	return strings.EqualFold(name[len(name)-len(extension):], extension)
+}
+
+// ReadSource reads a source file for AnalyzeSource, failing with the same
+// errors as AnalyzeFile
+func ReadSource(filePath string) ([]byte, error) {
+	content, err := readFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return content, nil
+}
+
+// readFile reads a source file, returning a *DirectoryError for directories
+// instead of the less helpful error of reading one
+func readFile(filePath string) ([]byte, error) {
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		return nil, &DirectoryError{Path: filePath}
+	}
+	return os.ReadFile(filePath)
 }
 
 // UnsupportedFileError is returned when no analyzer supports the given file
@@ -51,3 +292,22 @@ type UnsupportedFileError struct {
 func (e *UnsupportedFileError) Error() string {
 	return "unsupported file type: " + e.FilePath
 }
+
+// DirectoryError is returned when a directory is given where a source file
+// is expected
+type DirectoryError struct {
+	Path string
+}
+
+func (e *DirectoryError) Error() string {
+	return e.Path + " is a directory, not a file; analyze the files in it, or pass it to 'abc analyze', which walks directories recursively"
+}
+
+// TimeoutError is returned when analyzing a file exceeds its time limit
+type TimeoutError struct {
+	FilePath string
+}
+
+func (e *TimeoutError) Error() string {
+	return "analysis timed out: " + e.FilePath
+}
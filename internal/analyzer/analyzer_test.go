@@ -0,0 +1,323 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+func TestHasExtension(t *testing.T) {
+	tests := []struct {
+		name      string
+		filePath  string
+		extension string
+		want      bool
+	}{
+		{"matching extension", "main.go", ".go", true},
+		{"extension without dot", "main.go", "go", true},
+		{"nested path", "internal/analyzer/golang.go", ".go", true},
+		{"uppercase file extension", "main.GO", ".go", true},
+		{"uppercase extension argument", "main.go", ".GO", true},
+		{"mixed case", "App.Ts", "ts", true},
+		{"other extension", "main.py", ".go", false},
+		{"name without a dot", "foogo", ".go", false},
+		{"name without a dot equal to extension", "go", "go", false},
+		{"name equal to extension", ".go", ".go", false},
+		{"dot file in a directory", "dir/.go", ".go", false},
+		{"extension longer than name", "a.c", ".cpp", false},
+		{"extension longer than path", "x", ".go", false},
+		{"suffix of a longer extension", "main.ago", ".go", false},
+		{"multi-part extension", "types.d.ts", ".ts", true},
+		{"directory named like a file", "pkg.go/main", ".go", false},
+		{"empty path", "", ".go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasExtension(tt.filePath, tt.extension); got != tt.want {
+				t.Errorf("HasExtension(%q, %q) = %v, want %v", tt.filePath, tt.extension, got, tt.want)
+			}
+		})
+	}
+}
+
+// counts holds the A, B and C counts expected of a fixture function
+type counts struct {
+	a, b, c int
+}
+
+// countsOf returns the A, B and C counts of the metrics
+func countsOf(m metrics.ABCMetrics) counts {
+	return counts{a: m.Assignments, b: m.Branches, c: m.Conditions}
+}
+
+// fixture returns the path of a file in the test-files directory
+func fixture(name string) string {
+	return filepath.Join("..", "..", "test-files", name)
+}
+
+// analyzeFunctions analyzes a Go fixture per function with the given
+// counting rules and returns the functions by name
+func analyzeFunctions(t *testing.T, name string, cfg CountConfig) map[string]metrics.FunctionMetrics {
+	t.Helper()
+	functions, err := NewGoAnalyzerWithConfig(cfg).AnalyzeFileByFunction(fixture(name))
+	if err != nil {
+		t.Fatalf("AnalyzeFileByFunction(%s): %v", name, err)
+	}
+
+	byName := make(map[string]metrics.FunctionMetrics, len(functions))
+	for _, fn := range functions {
+		byName[fn.Name] = fn
+	}
+	return byName
+}
+
+// analyzeFixture analyzes a whole fixture with the given counting rules,
+// using the analyzer for its extension
+func analyzeFixture(t *testing.T, name string, cfg CountConfig) metrics.ABCMetrics {
+	t.Helper()
+	a, err := GetAnalyzerForFileWithConfig(fixture(name), cfg)
+	if err != nil {
+		t.Fatalf("GetAnalyzerForFileWithConfig(%s): %v", name, err)
+	}
+	m, err := a.AnalyzeFile(fixture(name))
+	if err != nil {
+		t.Fatalf("AnalyzeFile(%s): %v", name, err)
+	}
+	return m
+}
+
+// countCase is the expected counts of a fixture function, or of the whole
+// fixture when function is empty, under a set of counting rules
+type countCase struct {
+	file     string
+	function string
+	cfg      CountConfig
+	want     counts
+}
+
+// detailCase is the expected detail texts or contexts of a fixture function,
+// or of the whole fixture when function is empty, as extracted by list
+type detailCase struct {
+	file     string
+	function string
+	cfg      CountConfig
+	list     func(metrics.ABCMetrics) []string
+	want     []string
+}
+
+// analyzeCase returns the metrics of the function of a fixture, or of the
+// whole fixture when function is empty
+func analyzeCase(t *testing.T, file, function string, cfg CountConfig) metrics.ABCMetrics {
+	t.Helper()
+	if function == "" {
+		return analyzeFixture(t, file, cfg)
+	}
+	fn, ok := analyzeFunctions(t, file, cfg)[function]
+	if !ok {
+		t.Fatalf("function %s not found in %s", function, file)
+	}
+	return fn.Metrics
+}
+
+// caseName names the subtest of a fixture function or whole fixture
+func caseName(file, function string) string {
+	if function == "" {
+		return file
+	}
+	return file + "/" + function
+}
+
+// checkCounts runs a subtest per case, comparing the counts with the
+// expected ones
+func checkCounts(t *testing.T, tests []countCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(caseName(tt.file, tt.function), func(t *testing.T) {
+			if got := countsOf(analyzeCase(t, tt.file, tt.function, tt.cfg)); got != tt.want {
+				t.Errorf("got A=%d B=%d C=%d, want A=%d B=%d C=%d", got.a, got.b, got.c, tt.want.a, tt.want.b, tt.want.c)
+			}
+		})
+	}
+}
+
+// checkDetails runs a subtest per case, comparing the extracted details
+// with the expected ones
+func checkDetails(t *testing.T, tests []detailCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(caseName(tt.file, tt.function), func(t *testing.T) {
+			if got := tt.list(analyzeCase(t, tt.file, tt.function, tt.cfg)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// texts returns the text of every detail, in source order
+func texts(details []metrics.MetricDetail) []string {
+	var result []string
+	for _, d := range metrics.SortDetails(details) {
+		result = append(result, d.Text)
+	}
+	return result
+}
+
+// contexts returns the context of every detail, in source order
+func contexts(details []metrics.MetricDetail) []string {
+	var result []string
+	for _, d := range metrics.SortDetails(details) {
+		result = append(result, d.Context)
+	}
+	return result
+}
+
+// assignments returns the texts of the assignments, in source order
+func assignments(m metrics.ABCMetrics) []string {
+	return texts(m.AssignmentList)
+}
+
+// branches returns the texts of the branches, in source order
+func branches(m metrics.ABCMetrics) []string {
+	return texts(m.BranchList)
+}
+
+// conditions returns the texts of the conditions, in source order
+func conditions(m metrics.ABCMetrics) []string {
+	return texts(m.ConditionList)
+}
+
+// assignmentContexts returns the contexts of the assignments, in source order
+func assignmentContexts(m metrics.ABCMetrics) []string {
+	return contexts(m.AssignmentList)
+}
+
+// conditionContexts returns the contexts of the conditions, in source order
+func conditionContexts(m metrics.ABCMetrics) []string {
+	return contexts(m.ConditionList)
+}
+
+// countsAndDetails is the counts and detail texts of an analyzed source
+type countsAndDetails struct {
+	counts      counts
+	assignments []string
+	branches    []string
+	conditions  []string
+}
+
+// sourceCase is the expected result of analyzing a snippet of source code
+type sourceCase struct {
+	name string
+	src  string
+	want countsAndDetails
+}
+
+// analyzeSource analyzes in-memory source with the analyzer, failing the
+// test instead of hanging when the analysis does not finish
+func analyzeSource(t *testing.T, a Analyzer, filename, src string) countsAndDetails {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m, err := AnalyzeSourceContext(ctx, a, filename, []byte(src))
+	if err != nil {
+		t.Fatalf("AnalyzeSource(%q): %v", src, err)
+	}
+	return countsAndDetails{
+		counts:      countsOf(m),
+		assignments: assignments(m),
+		branches:    branches(m),
+		conditions:  conditions(m),
+	}
+}
+
+// checkSources runs a subtest per case, analyzing its source with the
+// analyzer and comparing the result with the expected one
+func checkSources(t *testing.T, a Analyzer, filename string, tests []sourceCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := analyzeSource(t, a, filename, tt.src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// slowFunctionAnalyzer blocks every per-function analysis until released
+type slowFunctionAnalyzer struct {
+	release chan struct{}
+}
+
+// AnalyzeFileByFunction implements FunctionAnalyzer
+func (a slowFunctionAnalyzer) AnalyzeFileByFunction(string) ([]metrics.FunctionMetrics, error) {
+	<-a.release
+	return nil, nil
+}
+
+// AnalyzeSourceByFunction implements FunctionAnalyzer
+func (a slowFunctionAnalyzer) AnalyzeSourceByFunction(string, []byte) ([]metrics.FunctionMetrics, error) {
+	<-a.release
+	return nil, nil
+}
+
+// panickingAnalyzer panics on every source it is given
+type panickingAnalyzer struct {
+	*GoAnalyzer
+}
+
+// AnalyzeSource implements Analyzer
+func (panickingAnalyzer) AnalyzeSource(string, []byte) (metrics.ABCMetrics, error) {
+	panic("malformed input")
+}
+
+func TestAnalyzeSourceContextRecoversPanics(t *testing.T) {
+	_, err := AnalyzeSourceContext(context.Background(), panickingAnalyzer{}, "bad.go", nil)
+	if err == nil || err.Error() != "analyzer failed: malformed input" {
+		t.Errorf("got error %v, want the panic as an error", err)
+	}
+}
+
+func TestAnalyzeByFunctionWithTimeout(t *testing.T) {
+	slow := slowFunctionAnalyzer{release: make(chan struct{})}
+	defer close(slow.release)
+
+	_, err := AnalyzeSourceByFunctionWithTimeout(slow, "slow.go", nil, 10*time.Millisecond)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("got error %v, want a *TimeoutError", err)
+	}
+
+	functions, err := AnalyzeFileByFunctionWithTimeout(NewGoAnalyzer(), fixture("slices.go"), time.Minute)
+	if err != nil || len(functions) != 1 {
+		t.Errorf("got %d functions and error %v, want the window function", len(functions), err)
+	}
+}
+
+func TestSpecsOnlyChangeGo(t *testing.T) {
+	want := map[string]counts{
+		"default":     {4, 2, 5},
+		"fitzpatrick": {6, 4, 7},
+		"minimal":     {2, 1, 4},
+	}
+	for _, name := range SpecNames() {
+		cfg, err := SpecConfig(name)
+		if err != nil {
+			t.Fatalf("SpecConfig(%s): %v", name, err)
+		}
+		if got := countsOf(analyzeFixture(t, "specs.go", cfg)); got != want[name] {
+			t.Errorf("%s: specs.go got %+v, want %+v", name, got, want[name])
+		}
+
+		for _, file := range []string{"counting.ts", "counting.py", filepath.Join("c", "counting.c")} {
+			if got, base := analyzeFixture(t, file, cfg), analyzeFixture(t, file, CountConfig{}); !reflect.DeepEqual(got, base) {
+				t.Errorf("%s: %s differs from the default rules", name, file)
+			}
+		}
+	}
+}
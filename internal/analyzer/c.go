@@ -0,0 +1,481 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// CAnalyzer implements the Analyzer interface for C and C++ code. Like the
+// TypeScript analyzer it works on a token stream rather than a full syntax
+// tree. Preprocessor directives are skipped entirely, so macro bodies and
+// conditional compilation do not contribute to the counts.
+type CAnalyzer struct{}
+
+// NewCAnalyzer creates a new C/C++ analyzer
+func NewCAnalyzer() *CAnalyzer {
+	return &CAnalyzer{}
+}
+
+// SupportedExtensions returns the list of file extensions supported by this analyzer
+func (a *CAnalyzer) SupportedExtensions() []string {
+	return []string{".c", ".h", ".cpp", ".hpp", ".cc"}
+}
+
+// AnalyzeFile analyzes a C or C++ file and returns ABC metrics
+func (a *CAnalyzer) AnalyzeFile(filePath string) (metrics.ABCMetrics, error) {
+	// Read file content
+	content, err := readFile(filePath)
+	if err != nil {
+		return metrics.ABCMetrics{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return a.AnalyzeSource(filePath, content)
+}
+
+// AnalyzeSource analyzes C or C++ source code and returns ABC metrics. The
+// filename is only used for position information.
+func (a *CAnalyzer) AnalyzeSource(filename string, src []byte) (metrics.ABCMetrics, error) {
+	c := &cCounter{newTokenCounter(tokenizeC(src), cChainSeparators)}
+	c.count()
+	c.metrics.Lines = countLines(src)
+
+	return c.metrics, nil
+}
+
+// cPunctuators lists multi-character punctuators, longest first
+var cPunctuators = []string{
+	"<<=", ">>=", "...", "->*", "<=>",
+	"->", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||", "+=", "-=",
+	"*=", "/=", "%=", "&=", "|=", "^=", "::", ".*",
+}
+
+// cLexer turns C/C++ source into tokens. Comments and preprocessor
+// directives are dropped.
+type cLexer struct {
+	lexer
+
+	// lineStart is set while only whitespace has been seen on the current
+	// line, which is where a preprocessor directive may start
+	lineStart bool
+}
+
+// tokenizeC splits C/C++ source into tokens. It is lenient: unterminated
+// literals and comments simply end at the end of the input.
+func tokenizeC(src []byte) []lexToken {
+	l := &cLexer{lexer: newLexer(src), lineStart: true}
+	l.run()
+	return l.tokens
+}
+
+// run tokenizes the whole input
+func (l *cLexer) run() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		line, col := l.line, l.col
+
+		if c == '\n' {
+			l.advance(1)
+			l.lineStart = true
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v' {
+			l.advance(1)
+			continue
+		}
+
+		atLineStart := l.lineStart
+		l.lineStart = false
+
+		switch {
+		case c == '#' && atLineStart:
+			l.skipDirective()
+			l.lineStart = true
+
+		case c == '/' && l.peek(1) == '/':
+			l.skipLine()
+
+		case c == '/' && l.peek(1) == '*':
+			l.skipBlockComment()
+			l.lineStart = atLineStart
+
+		case c == '"' || c == '\'':
+			start := l.pos
+			l.scanQuoted(c)
+			l.emit(stringToken, start, line, col)
+
+		case isCIdentStart(l.src[l.pos:]):
+			start := l.pos
+			l.scanIdent(isCIdentPart)
+
+			// Literal prefixes such as L"...", u8'x' and raw strings R"(...)"
+			if l.pos < len(l.src) && (l.src[l.pos] == '"' || l.src[l.pos] == '\'') && isLiteralPrefix(string(l.src[start:l.pos])) {
+				if bytes.HasSuffix(l.src[start:l.pos], []byte("R")) && l.src[l.pos] == '"' {
+					l.scanRawString()
+				} else {
+					l.scanQuoted(l.src[l.pos])
+				}
+				l.emit(stringToken, start, line, col)
+				break
+			}
+			l.emit(identToken, start, line, col)
+
+		case c >= '0' && c <= '9' || c == '.' && l.peek(1) >= '0' && l.peek(1) <= '9':
+			start := l.pos
+			for l.pos < len(l.src) {
+				b := l.src[l.pos]
+				// Digit separators (1'000'000) are part of the number
+				if isCIdentPart(l.src[l.pos:]) || b == '.' || b == '\'' && isCIdentPart(l.src[l.pos+1:]) {
+					l.advance(1)
+					continue
+				}
+				// Exponent signs, as in 1e-9 or 0x1p+3
+				if (b == '+' || b == '-') && bytes.ContainsAny(l.src[l.pos-1:l.pos], "eEpP") {
+					l.advance(1)
+					continue
+				}
+				break
+			}
+			l.emit(numberToken, start, line, col)
+
+		default:
+			l.punct(l.matchPunct(cPunctuators), line, col)
+		}
+	}
+}
+
+// skipDirective consumes a preprocessor directive up to the end of its line,
+// following backslash line continuations
+func (l *cLexer) skipDirective() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+		switch {
+		case l.src[l.pos] == '\\' && (l.peek(1) == '\n' || l.peek(1) == '\r' && l.peek(2) == '\n'):
+			for l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+			l.advance(1)
+		case l.src[l.pos] == '/' && l.peek(1) == '*':
+			l.skipBlockComment()
+		default:
+			l.advance(1)
+		}
+	}
+}
+
+// skipBlockComment consumes a /* ... */ comment
+func (l *cLexer) skipBlockComment() {
+	l.advance(2)
+	for l.pos < len(l.src) && !(l.src[l.pos] == '*' && l.peek(1) == '/') {
+		l.advance(1)
+	}
+	l.advance(2)
+}
+
+// scanRawString consumes a C++ raw string literal R"delim( ... )delim"
+// starting at its opening quote
+func (l *cLexer) scanRawString() {
+	l.advance(1)
+	start := l.pos
+	for l.pos < len(l.src) && l.src[l.pos] != '(' && l.src[l.pos] != '\n' {
+		l.advance(1)
+	}
+	closing := []byte(")" + string(l.src[start:l.pos]) + "\"")
+
+	end := bytes.Index(l.src[l.pos:], closing)
+	if end < 0 {
+		l.advance(len(l.src) - l.pos)
+		return
+	}
+	l.advance(end + len(closing))
+}
+
+// isLiteralPrefix reports whether an identifier directly followed by a quote
+// is an encoding or raw string prefix
+func isLiteralPrefix(ident string) bool {
+	switch ident {
+	case "L", "u", "U", "u8", "R", "LR", "uR", "UR", "u8R":
+		return true
+	}
+	return false
+}
+
+// isCIdentStart reports whether the input starts with an identifier character
+func isCIdentStart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isCIdentPart reports whether the input starts with a character allowed
+// inside an identifier
+func isCIdentPart(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// cConditionKeywords are the keywords that count as conditions
+var cConditionKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "do": true, "switch": true, "case": true,
+}
+
+// cNonCallKeywords are keywords that may be followed by a parenthesis
+// without being a function call
+var cNonCallKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "return": true,
+	"sizeof": true, "alignof": true, "_Alignof": true, "alignas": true, "_Alignas": true,
+	"decltype": true, "typeid": true, "noexcept": true, "static_assert": true,
+	"_Static_assert": true, "catch": true, "throw": true, "case": true, "do": true,
+	"else": true, "defined": true, "__attribute__": true, "__declspec": true,
+	"asm": true, "__asm__": true, "_Generic": true, "operator": true, "co_return": true,
+	"co_await": true, "co_yield": true, "requires": true,
+}
+
+// cStatementKeywords are keywords that may directly precede a call without
+// the identifier before the call being its return type
+var cStatementKeywords = map[string]bool{
+	"return": true, "else": true, "case": true, "do": true, "new": true, "delete": true,
+	"throw": true, "goto": true, "co_return": true, "co_await": true, "co_yield": true,
+	"sizeof": true,
+}
+
+// cQualifiers are keywords that may follow the parameter list of a function
+// declaration
+var cQualifiers = map[string]bool{
+	"const": true, "volatile": true, "noexcept": true, "override": true, "final": true,
+	"mutable": true,
+}
+
+// cAssignmentOperators are the operators that count as assignments
+var cAssignmentOperators = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"<<=": true, ">>=": true, "&=": true, "|=": true, "^=": true,
+}
+
+// cChainSeparators join the names of a member or scope chain, each
+// rendered as written
+var cChainSeparators = map[string]string{".": ".", "->": "->", "::": "::"}
+
+// cCounter walks a token stream and collects ABC metrics
+type cCounter struct {
+	tokenCounter
+}
+
+// count classifies every token of the stream
+func (c *cCounter) count() {
+	for i, t := range c.tokens {
+		switch t.kind {
+		case identToken:
+			member := c.isMemberName(i)
+
+			switch {
+			case !member && t.text == "while" && c.closesDoBody(i):
+				// The while of a do-while loop belongs to the do statement
+			case !member && cConditionKeywords[t.text]:
+				c.addCondition(t, t.text+" statement")
+			case !member && t.text == "else" && c.textAt(i+1) != "if":
+				c.addCondition(t, "else branch")
+			case c.textAt(i+1) == "(" && (member || !cNonCallKeywords[t.text]) && !c.isDeclaration(i):
+				c.addBranch(t, c.chainName(i))
+			}
+
+		case punctToken:
+			switch {
+			case cAssignmentOperators[t.text]:
+				if t.text == "=" && c.isSpecialMemberDefinition(i) {
+					break
+				}
+				context := "Assignment (=)"
+				if t.text != "=" {
+					context = "Compound assignment (" + t.text + ")"
+				}
+				c.addAssignment(t, c.targetName(i), context)
+
+			case t.text == "++" || t.text == "--":
+				context := "Increment"
+				if t.text == "--" {
+					context = "Decrement"
+				}
+				c.addAssignment(t, c.incDecTarget(i), context)
+
+			case t.text == "&&" || t.text == "||":
+				c.addLogicalOperator(t)
+
+			case t.text == "?":
+				c.addCondition(t, "ternary operator")
+			}
+		}
+	}
+}
+
+// isMemberName reports whether the identifier at i is a member name
+// following . or ->, which is never a keyword
+func (c *cCounter) isMemberName(i int) bool {
+	prev := c.textAt(i - 1)
+	return prev == "." || prev == "->"
+}
+
+// closesDoBody reports whether the while keyword at i ends a do-while loop,
+// that is whether it follows the closing brace of a block opened by do
+func (c *cCounter) closesDoBody(i int) bool {
+	if c.textAt(i-1) != "}" {
+		return false
+	}
+
+	depth := 0
+	for j := i - 1; j >= 0; j-- {
+		if c.tokens[j].kind != punctToken {
+			continue
+		}
+		switch c.tokens[j].text {
+		case "}":
+			depth++
+		case "{":
+			depth--
+			if depth == 0 {
+				return c.textAt(j-1) == "do"
+			}
+		}
+	}
+	return false
+}
+
+// isSpecialMemberDefinition reports whether the = at i belongs to a pure
+// virtual, defaulted or deleted function declaration such as f() = 0,
+// f() = default or operator=, rather than an assignment
+func (c *cCounter) isSpecialMemberDefinition(i int) bool {
+	if c.textAt(i-1) == "operator" {
+		return true
+	}
+	switch c.textAt(i + 1) {
+	case "default", "delete":
+		return true
+	case "0":
+		return c.textAt(i+2) == ";" && (c.textAt(i-1) == ")" || cQualifiers[c.textAt(i-1)])
+	}
+	return false
+}
+
+// isDeclaration reports whether the identifier at i, followed by an opening
+// parenthesis, declares or defines a function instead of calling it. A
+// definition is followed by a body or, for constructors, an initializer
+// list; a declaration ends with a semicolon and is preceded by its return
+// type.
+func (c *cCounter) isDeclaration(i int) bool {
+	// Skip qualifiers after the parameter list
+	next := c.matchingParen(i+1) + 1
+	for cQualifiers[c.textAt(next)] {
+		next++
+	}
+
+	switch c.textAt(next) {
+	case "{", "->":
+		return true
+	case ":":
+		// Not a call in a conditional expression or a case label
+		prev := c.textAt(c.chainStart(i) - 1)
+		return prev != "?" && prev != "case"
+	case ";", "=":
+		return c.hasReturnType(i)
+	}
+	return false
+}
+
+// hasReturnType reports whether the qualified name ending at i is preceded
+// by a type, as in int f(void), char *Foo::bar(void) or
+// std::vector<int> values(void), or is a destructor
+func (c *cCounter) hasReturnType(i int) bool {
+	prev := c.chainStart(i) - 1
+	if c.textAt(prev) == "~" {
+		// Destructors start a declaration, ~f(x) in an expression does not
+		switch c.textAt(prev - 1) {
+		case "", ";", "{", "}", ":", "virtual":
+			return true
+		}
+		return false
+	}
+	for c.textAt(prev) == "*" || c.textAt(prev) == "&" {
+		prev--
+	}
+
+	switch {
+	case c.kindAt(prev) == identToken:
+		return !cStatementKeywords[c.tokens[prev].text]
+	case c.textAt(prev) == ">":
+		return c.isTemplateClose(prev)
+	}
+	return false
+}
+
+// isTemplateClose reports whether the > at i closes a template argument list
+// that starts the line, as in std::vector<int> values(void)
+func (c *cCounter) isTemplateClose(i int) bool {
+	depth := 0
+	for j := i; j >= 0; j-- {
+		switch c.tokens[j].text {
+		case ">":
+			depth++
+		case ">>":
+			depth += 2
+		case "<":
+			depth--
+			if depth == 0 {
+				return c.kindAt(j-1) == identToken && c.tokens[j].line == c.tokens[i].line
+			}
+		case ";", "{", "}", "(", ")":
+			return false
+		}
+	}
+	return false
+}
+
+// chainStart returns the index of the first identifier of the qualified or
+// member name ending at i, such as ns::Type::method or obj.field
+func (c *cCounter) chainStart(i int) int {
+	start := i
+	for start >= 2 {
+		sep := c.textAt(start - 1)
+		if sep == "~" && c.textAt(start-2) == "::" {
+			// Destructors, as in Foo::~Foo()
+			start--
+			continue
+		}
+		if (sep != "." && sep != "->" && sep != "::") || c.kindAt(start-2) != identToken {
+			break
+		}
+		start -= 2
+	}
+	return start
+}
+
+// targetName names the target of the assignment operator at i: the member
+// chain directly before it, or "expr" for anything more complex such as an
+// indexed or dereferenced expression in parentheses
+func (c *cCounter) targetName(i int) string {
+	if c.kindAt(i-1) == identToken {
+		return c.chainName(i - 1)
+	}
+	if c.textAt(i-1) == "]" {
+		// Name arrays after the indexed variable, as in buf[i] = 0
+		depth := 0
+		for j := i - 1; j >= 0; j-- {
+			switch c.tokens[j].text {
+			case "]":
+				depth++
+			case "[":
+				depth--
+				if depth == 0 && c.kindAt(j-1) == identToken {
+					return c.chainName(j-1) + "[]"
+				}
+			}
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return "expr"
+}
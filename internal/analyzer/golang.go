@@ -1,24 +1,31 @@
 package analyzer
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
-	"os"
-	"strings"
 
 	"github.com/abc-metrics/abc/internal/metrics"
 )
 
 // GoAnalyzer implements the Analyzer interface for Go code
-type GoAnalyzer struct{}
+type GoAnalyzer struct {
+	cfg CountConfig
+}
 
 // NewGoAnalyzer creates a new Go analyzer
 func NewGoAnalyzer() *GoAnalyzer {
 	return &GoAnalyzer{}
 }
 
+// NewGoAnalyzerWithConfig creates a new Go analyzer using the given counting rules
+func NewGoAnalyzerWithConfig(cfg CountConfig) *GoAnalyzer {
+	return &GoAnalyzer{cfg: cfg}
+}
+
 // SupportedExtensions returns the list of file extensions supported by this analyzer
 func (a *GoAnalyzer) SupportedExtensions() []string {
 	return []string{".go"}
@@ -27,36 +34,274 @@ func (a *GoAnalyzer) SupportedExtensions() []string {
 // AnalyzeFile analyzes a Go file and returns ABC metrics
 func (a *GoAnalyzer) AnalyzeFile(filePath string) (metrics.ABCMetrics, error) {
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := readFile(filePath)
 	if err != nil {
 		return metrics.ABCMetrics{}, fmt.Errorf("error reading file: %w", err)
 	}
 
-	// Parse the file
+	return a.AnalyzeSource(filePath, content)
+}
+
+// AnalyzeSource analyzes Go source code and returns ABC metrics. The filename
+// is only used for position information.
+func (a *GoAnalyzer) AnalyzeSource(filename string, src []byte) (metrics.ABCMetrics, error) {
+	// Parse the source
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filePath, content, 0)
+	f, syntaxErrors, err := parseGoSource(fset, filename, src)
 	if err != nil {
-		return metrics.ABCMetrics{}, fmt.Errorf("error parsing file: %w", err)
+		return metrics.ABCMetrics{}, err
 	}
 
 	// Analyze the AST
-	v := &goVisitor{
+	v := newGoVisitor(fset, a.cfg)
+	ast.Walk(v, f)
+	v.metrics.Lines = fset.File(f.FileStart).LineCount()
+	v.annotateNesting(fset.File(f.FileStart))
+
+	// Keep the metrics of the parseable portion, flagging them as partial
+	if len(syntaxErrors) > 0 {
+		v.metrics.Partial = true
+		v.metrics.Warnings = append(syntaxErrors, v.metrics.Warnings...)
+	}
+
+	return v.metrics, nil
+}
+
+// parseGoSource parses Go source tolerantly. Syntax errors do not abort the
+// analysis: the AST the parser managed to build is returned together with one
+// warning per error. Only sources without a valid package clause, which leave
+// nothing to analyze, are returned as an error.
+func parseGoSource(fset *token.FileSet, filename string, src []byte) (*ast.File, []metrics.MetricDetail, error) {
+	f, err := parser.ParseFile(fset, filename, src, parser.AllErrors)
+	if err == nil {
+		return f, nil, nil
+	}
+
+	// Without a package clause there is nothing to recover
+	var errorList scanner.ErrorList
+	if f == nil || f.Name == nil || f.Name.Name == "" || !errors.As(err, &errorList) {
+		return nil, nil, fmt.Errorf("error parsing file: %w", err)
+	}
+
+	warnings := make([]metrics.MetricDetail, 0, len(errorList))
+	for _, syntaxErr := range errorList {
+		warnings = append(warnings, metrics.MetricDetail{
+			Line:    syntaxErr.Pos.Line,
+			Col:     syntaxErr.Pos.Column,
+			Text:    syntaxErr.Msg,
+			Context: "syntax error, metrics only cover the parseable portion of the file",
+		})
+	}
+	return f, warnings, nil
+}
+
+// AnalyzeFileByFunction analyzes a Go file and returns ABC metrics for each
+// function and method declaration, in source order. Methods are named with
+// their receiver type, e.g. (*Server).Handle or Point.String. Function
+// literals are scopes of their own, named like the Go runtime does: the
+// closures of handle are handle.func1, handle.func2 and so on, and a closure
+// inside handle.func1 is handle.func1.1. Each follows its enclosing function,
+// whose metrics leave out the closure bodies. Syntax errors are reported as
+// warnings of the innermost function spanning them, which is flagged as
+// partial.
+func (a *GoAnalyzer) AnalyzeFileByFunction(filePath string) ([]metrics.FunctionMetrics, error) {
+	// Read file content
+	content, err := readFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return a.AnalyzeSourceByFunction(filePath, content)
+}
+
+// AnalyzeSourceByFunction analyzes Go source code like AnalyzeFileByFunction.
+// The filename is only used for position information.
+func (a *GoAnalyzer) AnalyzeSourceByFunction(filename string, src []byte) ([]metrics.FunctionMetrics, error) {
+	// Parse the source
+	fset := token.NewFileSet()
+	f, syntaxErrors, err := parseGoSource(fset, filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Analyze each function declaration separately
+	functions := []metrics.FunctionMetrics{}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		functions = a.analyzeScope(fset, functionName(fn), fn, fn, functions)
+	}
+
+	assignSyntaxErrors(functions, syntaxErrors)
+	return functions, nil
+}
+
+// assignSyntaxErrors adds each syntax error to the warnings of the innermost
+// function spanning its line and flags that function as partial. Closures
+// follow their enclosing function, so the last spanning one is the innermost.
+// Errors outside every function only show in the file metrics. Like in the
+// file metrics, syntax errors come first in the warnings, in source order.
+func assignSyntaxErrors(functions []metrics.FunctionMetrics, syntaxErrors []metrics.MetricDetail) {
+	for i := len(syntaxErrors) - 1; i >= 0; i-- {
+		syntaxErr := syntaxErrors[i]
+		innermost := -1
+		for j, fn := range functions {
+			if fn.StartLine <= syntaxErr.Line && syntaxErr.Line <= fn.EndLine {
+				innermost = j
+			}
+		}
+		if innermost < 0 {
+			continue
+		}
+
+		m := &functions[innermost].Metrics
+		m.Partial = true
+		m.Warnings = append([]metrics.MetricDetail{syntaxErr}, m.Warnings...)
+	}
+}
+
+// analyzeScope appends the metrics of a function, walking root but not the
+// function literals in it, followed by those of its closures. The span of
+// the scope is the one of fn.
+func (a *GoAnalyzer) analyzeScope(fset *token.FileSet, name string, fn, root ast.Node, functions []metrics.FunctionMetrics) []metrics.FunctionMetrics {
+	v := newGoVisitor(fset, a.cfg)
+	var closures []*ast.FuncLit
+	ast.Walk(closureSkipper{inner: v, closures: &closures}, root)
+	file := fset.File(fn.Pos())
+	start, end := fset.Position(fn.Pos()).Line, scopeEnd(file, fn)
+	v.metrics.Lines = end - start + 1
+	v.annotateNesting(file)
+
+	functions = append(functions, metrics.FunctionMetrics{
+		Name:      name,
+		StartLine: start,
+		EndLine:   end,
+		Metrics:   v.metrics,
+	})
+
+	// Top-level closures are numbered func1, func2, ...; nested ones 1, 2, ...
+	prefix := name + ".func"
+	if _, ok := fn.(*ast.FuncLit); ok {
+		prefix = name + "."
+	}
+	for i, lit := range closures {
+		functions = a.analyzeScope(fset, fmt.Sprintf("%s%d", prefix, i+1), lit, lit.Body, functions)
+	}
+	return functions
+}
+
+// scopeEnd returns the last line of fn. In partially parsed files the end of
+// a function can be missing, in which case it is taken to run to the end of
+// the file, and never before its start.
+func scopeEnd(file *token.File, fn ast.Node) int {
+	start := file.Line(fn.Pos())
+	if !fn.End().IsValid() || int(fn.End()) > file.Base()+file.Size() {
+		return max(file.LineCount(), start)
+	}
+	return max(file.Line(fn.End()), start)
+}
+
+// closureSkipper walks the AST with the inner visitor, except for function
+// literals, which are collected in source order instead of being visited
+type closureSkipper struct {
+	inner    ast.Visitor
+	closures *[]*ast.FuncLit
+}
+
+// Visit implements the ast.Visitor interface
+func (s closureSkipper) Visit(node ast.Node) ast.Visitor {
+	if lit, ok := node.(*ast.FuncLit); ok {
+		*s.closures = append(*s.closures, lit)
+		return nil
+	}
+
+	inner := s.inner.Visit(node)
+	if inner == nil {
+		return nil
+	}
+	return closureSkipper{inner: inner, closures: s.closures}
+}
+
+// functionName returns the display name of a function declaration, qualifying
+// methods with their receiver type
+func functionName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	pointer := false
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer = true
+		recv = star.X
+	}
+
+	// Drop type parameters of generic receivers such as List[T]
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+
+	typeName := "?"
+	if ident, ok := recv.(*ast.Ident); ok {
+		typeName = ident.Name
+	}
+
+	if pointer {
+		return "(*" + typeName + ")." + fn.Name.Name
+	}
+	return typeName + "." + fn.Name.Name
+}
+
+// newGoVisitor creates a visitor with empty metrics
+func newGoVisitor(fset *token.FileSet, cfg CountConfig) *goVisitor {
+	return &goVisitor{
 		metrics: metrics.ABCMetrics{
 			AssignmentList: []metrics.MetricDetail{},
 			BranchList:     []metrics.MetricDetail{},
 			ConditionList:  []metrics.MetricDetail{},
+			Warnings:       []metrics.MetricDetail{},
 		},
 		fset: fset,
+		cfg:  cfg,
 	}
-	ast.Walk(v, f)
-
-	return v.metrics, nil
 }
 
 // goVisitor implements the ast.Visitor interface for Go AST traversal
 type goVisitor struct {
 	metrics metrics.ABCMetrics
 	fset    *token.FileSet
+	cfg     CountConfig
+
+	// commAssign is the receive assignment of the select case being visited
+	commAssign *ast.AssignStmt
+
+	// typeCases holds the case clauses of the type switches seen so far, so
+	// they can be told apart from expression switch cases
+	typeCases map[*ast.CaseClause]bool
+
+	// initStmts holds the init and post statements whose assignments are
+	// left out with SkipInitAssignments
+	initStmts map[ast.Stmt]bool
+
+	// deferredCalls holds the calls of defer statements, left out with
+	// SkipDeferredCalls
+	deferredCalls map[*ast.CallExpr]bool
+
+	// bodies holds the bodies of the control statements seen so far, used
+	// to work out the nesting depth of conditions with TrackNesting
+	bodies []bodySpan
+}
+
+// bodySpan is the source range of a block nested in a control statement,
+// excluding its opening brace or case colon
+type bodySpan struct {
+	from, to token.Pos
 }
 
 // Visit implements the ast.Visitor interface
@@ -65,31 +310,106 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 		return nil
 	}
 
+	if v.cfg.TrackNesting {
+		v.recordBodies(node)
+	}
+
 	switch n := node.(type) {
+	// Function declarations are only inspected for lint warnings
+	case *ast.FuncDecl:
+		if v.cfg.LintGoroutines && n.Body != nil {
+			v.lintGoroutines(n)
+		}
+
 	// Assignments
 	case *ast.AssignStmt:
-		count := len(n.Lhs)
-		v.metrics.Assignments += count
+		if v.initStmts[n] {
+			break
+		}
+
+		// Tell declarations, plain reassignments and compound operators apart
+		kind := "Compound assignment"
+		switch {
+		case n == v.commAssign:
+			kind = "Receive assignment"
+		case n.Tok == token.DEFINE:
+			kind = "Definition"
+		case n.Tok == token.ASSIGN:
+			kind = "Assignment"
+		}
+
+		// Every target gets its own detail at its own position
+		targets := v.assignmentTargets(n.Lhs...)
+		for i, expr := range targets {
+			v.addAssignment(expr, fmt.Sprintf("%s (%s%s)", kind, n.Tok, variableOf(i, len(targets))))
+		}
+
+	// Return statements are exit points, counted as branches when enabled
+	case *ast.ReturnStmt:
+		if !v.cfg.CountReturns {
+			break
+		}
 
+		v.metrics.Branches++
 		pos := v.fset.Position(n.Pos())
-		varNames := make([]string, 0, len(n.Lhs))
-		for _, expr := range n.Lhs {
-			if ident, ok := expr.(*ast.Ident); ok {
-				varNames = append(varNames, ident.Name)
-			} else {
-				varNames = append(varNames, "expr")
-			}
+		v.metrics.BranchList = append(v.metrics.BranchList, metrics.MetricDetail{
+			Line:    pos.Line,
+			Col:     pos.Column,
+			Text:    "return",
+			Context: "return",
+		})
+
+	// Increment and decrement statements assign to their operand
+	case *ast.IncDecStmt:
+		if v.initStmts[n] {
+			break
+		}
+		v.metrics.Assignments++
+
+		pos := v.fset.Position(n.Pos())
+		name := targetName(n.X)
+		context := "Increment"
+		if n.Tok == token.DEC {
+			context = "Decrement"
 		}
 
 		v.metrics.AssignmentList = append(v.metrics.AssignmentList, metrics.MetricDetail{
 			Line:    pos.Line,
 			Col:     pos.Column,
-			Text:    strings.Join(varNames, ", "),
-			Context: fmt.Sprintf("Assignment (%d variables)", count),
+			Text:    name,
+			Context: context,
 		})
 
+	// Composite literal elements initialize values, counted when enabled
+	case *ast.CompositeLit:
+		if v.cfg.CountLiteralElements {
+			v.addLiteralElements(n)
+		}
+
+	// Select cases receiving into variables (case v, ok := <-ch) hold an
+	// assignment that is counted when the walk reaches it
+	case *ast.CommClause:
+		if assign, ok := n.Comm.(*ast.AssignStmt); ok {
+			v.commAssign = assign
+		}
+
+	// Deferred calls are counted when the walk reaches the call, unless
+	// they are skipped
+	case *ast.DeferStmt:
+		if v.cfg.SkipDeferredCalls {
+			if v.deferredCalls == nil {
+				v.deferredCalls = map[*ast.CallExpr]bool{}
+			}
+			v.deferredCalls[n.Call] = true
+		}
+
 	// Branches (function calls)
 	case *ast.CallExpr:
+		// Type conversions look like calls but are not branches
+		if isTypeConversion(n) || v.deferredCalls[n] {
+			break
+		}
+
 		v.metrics.Branches++
 
 		pos := v.fset.Position(n.Pos())
@@ -98,9 +418,15 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 		switch fn := n.Fun.(type) {
 		case *ast.Ident:
 			funcName = fn.Name
+		case *ast.FuncLit:
+			funcName = "func literal"
 		case *ast.SelectorExpr:
-			if x, ok := fn.X.(*ast.Ident); ok {
-				funcName = x.Name + "." + fn.Sel.Name
+			if name, ok := selectorChain(fn); ok {
+				funcName = name
+			} else if typeName, ok := compositeLitType(fn.X); ok {
+				funcName = "(" + typeName + ")." + fn.Sel.Name
+			} else if recv, ok := exprString(fn.X); ok {
+				funcName = recv + "." + fn.Sel.Name
 			} else {
 				funcName = fn.Sel.Name
 			}
@@ -124,6 +450,24 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 			Context: "Condition",
 		})
 
+		// A bare else is a decision path of its own; else-if chains are
+		// counted through the nested if statement instead
+		if elseBlock, ok := n.Else.(*ast.BlockStmt); ok {
+			v.metrics.Conditions++
+			pos := v.fset.Position(elseBlock.Pos())
+			v.metrics.ConditionList = append(v.metrics.ConditionList, metrics.MetricDetail{
+				Line:    pos.Line,
+				Col:     pos.Column,
+				Text:    "else branch",
+				Context: "Condition",
+			})
+		}
+
+		if v.cfg.CountGuards && isGuardClause(n) {
+			v.metrics.Guards++
+		}
+		v.skipInit(n.Init)
+
 	case *ast.ForStmt:
 		v.metrics.Conditions++
 		pos := v.fset.Position(n.Pos())
@@ -133,6 +477,7 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 			Text:    "for loop",
 			Context: "Condition",
 		})
+		v.skipInit(n.Init, n.Post)
 
 	case *ast.RangeStmt:
 		v.metrics.Conditions++
@@ -144,6 +489,11 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 			Context: "Condition",
 		})
 
+		// Loop variables bound with := or = are assigned on every iteration
+		if v.cfg.CountRangeAssignments && n.Tok != token.ILLEGAL {
+			v.addRangeAssignment(n)
+		}
+
 	case *ast.SwitchStmt:
 		v.metrics.Conditions++
 		pos := v.fset.Position(n.Pos())
@@ -153,6 +503,7 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 			Text:    "switch statement",
 			Context: "Condition",
 		})
+		v.skipInit(n.Init)
 
 	case *ast.TypeSwitchStmt:
 		v.metrics.Conditions++
@@ -164,6 +515,16 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 			Context: "Condition",
 		})
 
+		if v.typeCases == nil {
+			v.typeCases = map[*ast.CaseClause]bool{}
+		}
+		for _, clause := range n.Body.List {
+			if clause, ok := clause.(*ast.CaseClause); ok {
+				v.typeCases[clause] = true
+			}
+		}
+		v.skipInit(n.Init)
+
 	case *ast.SelectStmt:
 		v.metrics.Conditions++
 		pos := v.fset.Position(n.Pos())
@@ -176,12 +537,30 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 
 	case *ast.CaseClause:
 		if n.List != nil { // Skip default case
+			text := "case clause"
+			if v.typeCases[n] {
+				text = "type case"
+			}
 			v.metrics.Conditions++
 			pos := v.fset.Position(n.Pos())
 			v.metrics.ConditionList = append(v.metrics.ConditionList, metrics.MetricDetail{
 				Line:    pos.Line,
 				Col:     pos.Column,
-				Text:    "case clause",
+				Text:    text,
+				Context: "Condition",
+			})
+		}
+
+	case *ast.TypeAssertExpr:
+		// x.(T) checks the dynamic type, while the x.(type) guard of a type
+		// switch has no type and is covered by the switch itself
+		if n.Type != nil && !v.cfg.SkipTypeAssertions {
+			v.metrics.Conditions++
+			pos := v.fset.Position(n.Lparen)
+			v.metrics.ConditionList = append(v.metrics.ConditionList, metrics.MetricDetail{
+				Line:    pos.Line,
+				Col:     pos.Column,
+				Text:    "type assertion",
 				Context: "Condition",
 			})
 		}
@@ -202,7 +581,356 @@ func (v *goVisitor) Visit(node ast.Node) ast.Visitor {
 				Context: "Logical operator",
 			})
 		}
+
+		// Count comparisons as conditions on request
+		if v.cfg.CountComparisons && isComparison(n.Op) {
+			v.metrics.Conditions++
+			pos := v.fset.Position(n.OpPos)
+			v.metrics.ConditionList = append(v.metrics.ConditionList, metrics.MetricDetail{
+				Line:    pos.Line,
+				Col:     pos.Column,
+				Text:    n.Op.String(),
+				Context: "Comparison",
+			})
+		}
 	}
 
 	return v
 }
+
+// isComparison reports whether the operator compares its operands
+func isComparison(op token.Token) bool {
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return true
+	}
+	return false
+}
+
+// addRangeAssignment records each key/value target of a range loop as an
+// assignment detail of its own, positioned at the target. Loops declaring
+// new variables (:=) are labeled separately from loops assigning to
+// existing ones (=).
+func (v *goVisitor) addRangeAssignment(n *ast.RangeStmt) {
+	targets := v.assignmentTargets(n.Key, n.Value)
+
+	kind := "Range definition"
+	if n.Tok == token.ASSIGN {
+		kind = "Range assignment"
+	}
+
+	for i, expr := range targets {
+		v.addAssignment(expr, fmt.Sprintf("%s (%s%s)", kind, n.Tok, variableOf(i, len(targets))))
+	}
+}
+
+// assignmentTargets returns the targets of an assignment that count, leaving
+// out the missing key or value of a range loop and, with
+// SkipBlankAssignments, the blank identifier
+func (v *goVisitor) assignmentTargets(exprs ...ast.Expr) []ast.Expr {
+	targets := make([]ast.Expr, 0, len(exprs))
+	for _, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+		if ident, ok := expr.(*ast.Ident); ok && ident.Name == "_" && v.cfg.SkipBlankAssignments {
+			continue
+		}
+		targets = append(targets, expr)
+	}
+	return targets
+}
+
+// addAssignment records an assignment to the target expression, positioned
+// at the target itself
+func (v *goVisitor) addAssignment(target ast.Expr, context string) {
+	name := targetName(target)
+	v.metrics.Assignments++
+	pos := v.fset.Position(target.Pos())
+	v.metrics.AssignmentList = append(v.metrics.AssignmentList, metrics.MetricDetail{
+		Line:    pos.Line,
+		Col:     pos.Column,
+		Text:    name,
+		Context: context,
+	})
+}
+
+// addLiteralElements records every element of a composite literal as an
+// assignment. Keyed elements are named after their key, positional ones after
+// their index.
+func (v *goVisitor) addLiteralElements(n *ast.CompositeLit) {
+	for i, elt := range n.Elts {
+		text := fmt.Sprintf("[%d]", i)
+		context := "Literal element"
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			context = "Literal element (key: value)"
+			text = targetName(kv.Key)
+		}
+
+		v.metrics.Assignments++
+		pos := v.fset.Position(elt.Pos())
+		v.metrics.AssignmentList = append(v.metrics.AssignmentList, metrics.MetricDetail{
+			Line:    pos.Line,
+			Col:     pos.Column,
+			Text:    text,
+			Context: context,
+		})
+	}
+}
+
+// targetName names an assignment target or literal key in the details the
+// way branches are named, such as config.Server.Timeout or hits[key], and as
+// "expr" when the expression cannot be rendered
+func targetName(expr ast.Expr) string {
+	if name, ok := exprString(expr); ok {
+		return name
+	}
+	return "expr"
+}
+
+// variableOf describes the position of target i among count targets of a
+// multi-variable assignment, e.g. ", variable 2 of 3", and is empty for a
+// single target
+func variableOf(i, count int) string {
+	if count == 1 {
+		return ""
+	}
+	return fmt.Sprintf(", variable %d of %d", i+1, count)
+}
+
+// builtinTypes are the predeclared type names that can be used in conversions
+var builtinTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true,
+	"int16": true, "int32": true, "int64": true, "rune": true, "string": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true,
+}
+
+// isTypeConversion reports whether a call expression is a conversion to a
+// predeclared or composite type, such as int(x), []byte(s) or (*T)(p).
+// Conversions to named types cannot be told apart from function calls
+// without type information and are still counted as branches.
+func isTypeConversion(call *ast.CallExpr) bool {
+	fun := call.Fun
+	for {
+		paren, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren.X
+	}
+
+	switch fn := fun.(type) {
+	case *ast.Ident:
+		return builtinTypes[fn.Name]
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType, *ast.StarExpr:
+		return true
+	}
+	return false
+}
+
+// recordBodies remembers the blocks nested in a control statement. The
+// body of an if, its bare else block, loop bodies and the statements of case
+// and select clauses each add a level; the if of an else-if chain stays at
+// the level of the chain.
+func (v *goVisitor) recordBodies(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.IfStmt:
+		v.bodies = append(v.bodies, bodySpan{n.Body.Lbrace + 1, n.Body.End()})
+		if elseBlock, ok := n.Else.(*ast.BlockStmt); ok {
+			v.bodies = append(v.bodies, bodySpan{elseBlock.Lbrace + 1, elseBlock.End()})
+		}
+	case *ast.ForStmt:
+		v.bodies = append(v.bodies, bodySpan{n.Body.Lbrace + 1, n.Body.End()})
+	case *ast.RangeStmt:
+		v.bodies = append(v.bodies, bodySpan{n.Body.Lbrace + 1, n.Body.End()})
+	case *ast.CaseClause:
+		v.bodies = append(v.bodies, bodySpan{n.Colon + 1, n.End()})
+	case *ast.CommClause:
+		v.bodies = append(v.bodies, bodySpan{n.Colon + 1, n.End()})
+	}
+}
+
+// annotateNesting appends the nesting depth to the context of every
+// condition and records the deepest one, when TrackNesting is set. A
+// condition outside any control statement body is at depth 1.
+func (v *goVisitor) annotateNesting(file *token.File) {
+	if !v.cfg.TrackNesting {
+		return
+	}
+	for i := range v.metrics.ConditionList {
+		d := &v.metrics.ConditionList[i]
+		pos := file.LineStart(d.Line) + token.Pos(d.Col-1)
+
+		depth := 1
+		for _, body := range v.bodies {
+			if body.from <= pos && pos < body.to {
+				depth++
+			}
+		}
+		d.Context = fmt.Sprintf("%s, depth %d", d.Context, depth)
+		v.metrics.MaxNesting = max(v.metrics.MaxNesting, depth)
+	}
+}
+
+// skipInit marks the init or post statements of an if, switch or for
+// statement, so their assignments are not counted with SkipInitAssignments
+func (v *goVisitor) skipInit(stmts ...ast.Stmt) {
+	if !v.cfg.SkipInitAssignments {
+		return
+	}
+	if v.initStmts == nil {
+		v.initStmts = map[ast.Stmt]bool{}
+	}
+	for _, stmt := range stmts {
+		if stmt != nil {
+			v.initStmts[stmt] = true
+		}
+	}
+}
+
+// isGuardClause reports whether an if statement is an early-return guard:
+// no else branch and a body consisting of a single return statement
+func isGuardClause(n *ast.IfStmt) bool {
+	if n.Else != nil || len(n.Body.List) != 1 {
+		return false
+	}
+	_, ok := n.Body.List[0].(*ast.ReturnStmt)
+	return ok
+}
+
+// selectorChain renders a chain of identifiers joined by selectors, such as
+// pkg.Func or pkg.Type.Method, as a dotted name. It reports false when the
+// chain contains anything other than identifiers.
+func selectorChain(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name, true
+	case *ast.SelectorExpr:
+		x, ok := selectorChain(e.X)
+		if !ok {
+			return "", false
+		}
+		return x + "." + e.Sel.Name, true
+	}
+	return "", false
+}
+
+// exprString renders an expression such as the call receiver a.b().c or the
+// assignment target handlers[i] in a compact form: call arguments are elided
+// as (...) and indexes other than names and literals as [...]. It reports
+// false for expressions it does not render, such as function literals.
+func exprString(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name, true
+	case *ast.BasicLit:
+		return e.Value, true
+	case *ast.SelectorExpr:
+		x, ok := exprString(e.X)
+		if !ok {
+			return "", false
+		}
+		return x + "." + e.Sel.Name, true
+	case *ast.CallExpr:
+		fun, ok := exprString(e.Fun)
+		if !ok {
+			return "", false
+		}
+		if len(e.Args) > 0 {
+			return fun + "(...)", true
+		}
+		return fun + "()", true
+	case *ast.IndexExpr:
+		x, ok := exprString(e.X)
+		if !ok {
+			return "", false
+		}
+		// Only short indexes are spelled out, anything else is elided
+		index := "..."
+		switch e.Index.(type) {
+		case *ast.Ident, *ast.BasicLit, *ast.SelectorExpr:
+			if text, ok := exprString(e.Index); ok {
+				index = text
+			}
+		}
+		return x + "[" + index + "]", true
+	case *ast.IndexListExpr:
+		x, ok := exprString(e.X)
+		if !ok {
+			return "", false
+		}
+		return x + "[...]", true
+	case *ast.ParenExpr:
+		x, ok := exprString(e.X)
+		if !ok {
+			return "", false
+		}
+		return "(" + x + ")", true
+	case *ast.StarExpr:
+		x, ok := exprString(e.X)
+		if !ok {
+			return "", false
+		}
+		return "*" + x, true
+	}
+	return "", false
+}
+
+// compositeLitType returns the type name of a composite literal receiver such
+// as (&Buffer{}) or Point{}, looking through parentheses and the address
+// operator. It reports false for any other expression.
+func compositeLitType(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return compositeLitType(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return compositeLitType(e.X)
+		}
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return selectorChain(e.Type)
+		}
+	}
+	return "", false
+}
+
+// lintGoroutines warns when a function launches a goroutine but contains
+// neither a Wait call (sync.WaitGroup, errgroup) nor a channel receive that
+// could join it. This is a heuristic and may report false positives.
+func (v *goVisitor) lintGoroutines(fn *ast.FuncDecl) {
+	var goStmt *ast.GoStmt
+	joined := false
+
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.GoStmt:
+			if goStmt == nil {
+				goStmt = n
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				joined = true
+			}
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Wait" {
+				joined = true
+			}
+		}
+		return true
+	})
+
+	if goStmt == nil || joined {
+		return
+	}
+
+	pos := v.fset.Position(goStmt.Pos())
+	v.metrics.Warnings = append(v.metrics.Warnings, metrics.MetricDetail{
+		Line:    pos.Line,
+		Col:     pos.Column,
+		Text:    fn.Name.Name,
+		Context: "goroutine started without a WaitGroup wait or channel receive to join it",
+	})
+}
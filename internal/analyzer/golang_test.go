@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestGoAssignments(t *testing.T) {
+	checkCounts(t, []countCase{
+		{"slices.go", "window", CountConfig{}, counts{2, 1, 0}},
+		{"fields.go", "applyDefaults", CountConfig{}, counts{5, 0, 0}},
+		{"fields.go", "renameAll", CountConfig{}, counts{2, 0, 0}},
+		{"bool_map.go", "pick", CountConfig{}, counts{1, 0, 0}},
+		{"select.go", "drain", CountConfig{}, counts{4, 1, 2}},
+		{"guard_init.go", "guardWithInit", CountConfig{}, counts{2, 1, 2}},
+		{"incdec.go", "tally", CountConfig{}, counts{7, 2, 2}},
+		{"assign_ops.go", "accumulate", CountConfig{}, counts{7, 0, 1}},
+	})
+
+	checkDetails(t, []detailCase{
+		{"slices.go", "window", CountConfig{}, assignments, []string{"buf", "part"}},
+		{"fields.go", "applyDefaults", CountConfig{}, assignments, []string{
+			"config.Name", "config.Server.Timeout", "config.Server.TLS.Enabled", "timeout", "config.Server.Timeout",
+		}},
+		{"fields.go", "renameAll", CountConfig{}, assignments, []string{
+			`configs["main"].Name`, "configs[...].Server.Timeout",
+		}},
+		{"select.go", "drain", CountConfig{}, assignmentContexts, []string{
+			"Definition (:=)", "Receive assignment (:=, variable 1 of 2)", "Receive assignment (:=, variable 2 of 2)", "Assignment (=)",
+		}},
+		{"guard_init.go", "guardWithInit", CountConfig{}, assignments, []string{"v", "err"}},
+		{"incdec.go", "tally", CountConfig{}, assignments, []string{
+			"i", "i", "c.total", "c.hits[...]", "counts[i]", "remaining", "remaining",
+		}},
+		{"incdec.go", "tally", CountConfig{}, assignmentContexts, []string{
+			"Definition (:=)", "Increment", "Increment", "Increment", "Decrement", "Definition (:=)", "Decrement",
+		}},
+		{"assign_ops.go", "accumulate", CountConfig{}, assignments, []string{
+			"sum", "count", "sum", "count", "mask", "mask", "sum",
+		}},
+		{"assign_ops.go", "accumulate", CountConfig{}, assignmentContexts, []string{
+			"Definition (:=, variable 1 of 2)", "Definition (:=, variable 2 of 2)", "Compound assignment (+=)",
+			"Assignment (=)", "Definition (:=)", "Compound assignment (<<=)", "Assignment (=)",
+		}},
+	})
+}
+
+func TestGoBranches(t *testing.T) {
+	checkCounts(t, []countCase{
+		{"method_expr.go", "formatReadings", CountConfig{}, counts{2, 3, 0}},
+		{"literal_receiver.go", "freshReceivers", CountConfig{}, counts{0, 3, 0}},
+		{"higher_order.go", "apply", CountConfig{}, counts{0, 1, 0}},
+		{"higher_order.go", "applyIfSet", CountConfig{}, counts{0, 1, 1}},
+		{"defer.go", "deferDirect", CountConfig{}, counts{0, 1, 0}},
+		{"defer.go", "deferClosure", CountConfig{}, counts{0, 1, 0}},
+		{"defer.go", "deferClosure.func1", CountConfig{}, counts{0, 1, 1}},
+		{"defer.go", "", CountConfig{}, counts{0, 3, 1}},
+		{"conversions.go", "celsiusToString", CountConfig{}, counts{3, 2, 0}},
+		{"closures.go", "sortedNames", CountConfig{}, counts{1, 2, 0}},
+		{"closures.go", "sortedNames.func1", CountConfig{}, counts{0, 4, 1}},
+		{"closures.go", "sortedNames.func2", CountConfig{}, counts{0, 1, 1}},
+		{"closures.go", "sortedNames.func2.1", CountConfig{}, counts{0, 0, 2}},
+		{"closures.go", "mapNames", CountConfig{}, counts{2, 3, 1}},
+		{"closures.go", "retry", CountConfig{}, counts{1, 1, 1}},
+		{"closures.go", "retry.func1", CountConfig{}, counts{2, 1, 1}},
+		{"closures.go", "", CountConfig{}, counts{6, 12, 7}},
+	})
+
+	checkDetails(t, []detailCase{
+		{"method_expr.go", "formatReadings", CountConfig{}, branches, []string{"time.Time.Format", "celsius.String", "t.Format"}},
+		{"slices.go", "window", CountConfig{}, branches, []string{"make"}},
+		{"literal_receiver.go", "freshReceivers", CountConfig{}, branches, []string{
+			"(bytes.Buffer).WriteString", "(strings.Builder).WriteString", "(point).Sum",
+		}},
+		{"higher_order.go", "apply", CountConfig{}, branches, []string{"f"}},
+		{"defer.go", "", CountConfig{}, branches, []string{"t.Rollback", "func literal", "t.Rollback"}},
+		{"guard_init.go", "guardWithInit", CountConfig{}, branches, []string{"lookup"}},
+		{"conversions.go", "celsiusToString", CountConfig{}, branches, []string{"strings.ToUpper", "consume"}},
+		{"call_names.go", "chainedCalls", CountConfig{}, branches, []string{
+			"newBuilder().add(...).add(...).String", "newBuilder().add(...).add", "newBuilder().add", "newBuilder",
+		}},
+		{"call_names.go", "indexedCalls", CountConfig{}, branches, []string{
+			"bs[i].add", `byName["main"].add`, "bs[...].add", "bs[...].add",
+		}},
+		{"call_names.go", "parenthesizedCall", CountConfig{}, branches, []string{"(*b).String"}},
+		{"closures.go", "sortedNames", CountConfig{}, branches, []string{"sort.Slice", "mapNames"}},
+		{"closures.go", "retry.func1", CountConfig{}, branches, []string{"attempt"}},
+	})
+}
+
+func TestGoExprNames(t *testing.T) {
+	tests := []struct {
+		expr   string
+		render func(ast.Expr) (string, bool)
+		want   string
+		ok     bool
+	}{
+		{"strings.ToUpper", selectorChain, "strings.ToUpper", true},
+		{"time.Time.Format", selectorChain, "time.Time.Format", true},
+		{"a.b().c", selectorChain, "", false},
+		{"a.b().c", exprString, "a.b().c", true},
+		{"a.b(x, y).c", exprString, "a.b(...).c", true},
+		{"handlers[i]", exprString, "handlers[i]", true},
+		{`m["key"].v`, exprString, `m["key"].v`, true},
+		{"cfg.items[cfg.n]", exprString, "cfg.items[cfg.n]", true},
+		{"xs[i+1]", exprString, "xs[...]", true},
+		{"pair[int, string]", exprString, "pair[...]", true},
+		{"(*p).x", exprString, "(*p).x", true},
+		{"func() {}", exprString, "", false},
+		{"(&bytes.Buffer{})", compositeLitType, "bytes.Buffer", true},
+		{"point{1, 2}", compositeLitType, "point", true},
+		{"[]int{1}", compositeLitType, "", false},
+		{"-point{}", compositeLitType, "", false},
+	}
+
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseExpr(%s): %v", tt.expr, err)
+		}
+		got, ok := tt.render(expr)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %q, %v; want %q, %v", tt.expr, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGoConditions(t *testing.T) {
+	checkCounts(t, []countCase{
+		{"fallthrough.go", "bonusPoints", CountConfig{}, counts{4, 0, 4}},
+		{"else_if.go", "gradeChain", CountConfig{}, counts{4, 0, 3}},
+		{"else_if.go", "gradeChainWithElse", CountConfig{}, counts{4, 0, 4}},
+		{"else_if.go", "sign", CountConfig{}, counts{2, 0, 1}},
+		{"else_if.go", "signWithElse", CountConfig{}, counts{2, 0, 2}},
+		{"guards.go", "validate", CountConfig{}, counts{0, 4, 3}},
+		{"type_switch.go", "describe", CountConfig{}, counts{1, 1, 3}},
+		{"type_switch.go", "describeShort", CountConfig{}, counts{0, 0, 2}},
+		{"type_switch.go", "asString", CountConfig{}, counts{2, 0, 2}},
+		{"type_switch.go", "mustInt", CountConfig{}, counts{0, 0, 1}},
+	})
+
+	checkDetails(t, []detailCase{
+		{"fallthrough.go", "bonusPoints", CountConfig{}, conditions, []string{
+			"switch statement", "case clause", "case clause", "case clause",
+		}},
+		{"guard_init.go", "guardWithInit", CountConfig{}, conditions, []string{"if statement", "&&"}},
+		{"type_switch.go", "describe", CountConfig{}, conditions, []string{"type switch", "type case", "type case"}},
+		{"type_switch.go", "asString", CountConfig{}, conditions, []string{"type assertion", "if statement"}},
+	})
+}
+
+func TestGoCountRules(t *testing.T) {
+	withRange := CountConfig{CountRangeAssignments: true}
+	withReturns := CountConfig{CountReturns: true}
+	withLiterals := CountConfig{CountLiteralElements: true}
+	skipInit := CountConfig{SkipInitAssignments: true}
+	withComparisons := CountConfig{CountComparisons: true}
+	skipBlank := CountConfig{SkipBlankAssignments: true}
+	checkCounts(t, []countCase{
+		{"range.go", "rangeForms", CountConfig{}, counts{0, 3, 4}},
+		{"range.go", "rangeForms", withRange, counts{5, 3, 4}},
+		{"range.go", "rangeForms", CountConfig{CountRangeAssignments: true, SkipBlankAssignments: true}, counts{4, 3, 4}},
+		{"select.go", "closed", CountConfig{}, counts{2, 0, 1}},
+		{"select.go", "closed", skipBlank, counts{1, 0, 1}},
+		{"select.go", "drain", skipBlank, counts{4, 1, 2}},
+		{"range.go", "lastPair", CountConfig{}, counts{0, 0, 1}},
+		{"range.go", "lastPair", withRange, counts{2, 0, 1}},
+		{"defer.go", "deferDirect", CountConfig{SkipDeferredCalls: true}, counts{0, 0, 0}},
+		{"guards.go", "validate", CountConfig{CountGuards: true}, counts{0, 4, 3}},
+		{"returns.go", "clampOnce", CountConfig{}, counts{3, 0, 2}},
+		{"returns.go", "clampOnce", withReturns, counts{3, 1, 2}},
+		{"returns.go", "clampEarly", CountConfig{}, counts{0, 0, 2}},
+		{"returns.go", "clampEarly", withReturns, counts{0, 3, 2}},
+		{"returns.go", "record", withReturns, counts{1, 0, 0}},
+		{"type_switch.go", "asString", CountConfig{SkipTypeAssertions: true}, counts{2, 0, 1}},
+		{"type_switch.go", "mustInt", CountConfig{SkipTypeAssertions: true}, counts{0, 0, 0}},
+		{"type_switch.go", "describe", CountConfig{SkipTypeAssertions: true}, counts{1, 1, 3}},
+		{"literals.go", "mapLiteral", CountConfig{}, counts{1, 0, 0}},
+		{"literals.go", "mapLiteral", withLiterals, counts{5, 0, 0}},
+		{"literals.go", "structLiteral", CountConfig{}, counts{1, 0, 0}},
+		{"literals.go", "structLiteral", withLiterals, counts{3, 0, 0}},
+		{"literals.go", "sliceLiteral", CountConfig{}, counts{1, 0, 0}},
+		{"literals.go", "sliceLiteral", withLiterals, counts{7, 0, 0}},
+		{"init_stmts.go", "ifInit", CountConfig{}, counts{2, 0, 2}},
+		{"init_stmts.go", "ifInit", skipInit, counts{0, 0, 2}},
+		{"init_stmts.go", "switchInit", CountConfig{}, counts{1, 1, 2}},
+		{"init_stmts.go", "switchInit", skipInit, counts{0, 1, 2}},
+		{"init_stmts.go", "typeSwitchInit", CountConfig{}, counts{2, 0, 2}},
+		{"init_stmts.go", "typeSwitchInit", skipInit, counts{1, 0, 2}},
+		{"init_stmts.go", "forInitPost", CountConfig{}, counts{4, 0, 1}},
+		{"init_stmts.go", "forInitPost", skipInit, counts{2, 0, 1}},
+		{"comparisons.go", "inRange", CountConfig{}, counts{0, 0, 1}},
+		{"comparisons.go", "inRange", withComparisons, counts{0, 0, 3}},
+		{"comparisons.go", "clampScore", CountConfig{}, counts{2, 0, 2}},
+		{"comparisons.go", "clampScore", withComparisons, counts{2, 0, 4}},
+		{"comparisons.go", "sameCell", CountConfig{}, counts{1, 0, 1}},
+		{"comparisons.go", "sameCell", withComparisons, counts{1, 0, 3}},
+		{"comparisons.go", "classify", CountConfig{}, counts{1, 0, 3}},
+		{"comparisons.go", "classify", withComparisons, counts{1, 0, 5}},
+		{"comparisons.go", "", CountConfig{}, counts{4, 0, 7}},
+		{"comparisons.go", "", withComparisons, counts{4, 0, 15}},
+	})
+
+	checkDetails(t, []detailCase{
+		{"range.go", "rangeForms", withRange, assignments, []string{"i", "i", "x", "_", "x"}},
+		{"range.go", "rangeForms", CountConfig{CountRangeAssignments: true, SkipBlankAssignments: true}, assignments, []string{"i", "i", "x", "x"}},
+		{"select.go", "closed", CountConfig{}, assignmentContexts, []string{
+			"Receive assignment (:=, variable 1 of 2)", "Receive assignment (:=, variable 2 of 2)",
+		}},
+		{"select.go", "closed", skipBlank, assignmentContexts, []string{"Receive assignment (:=)"}},
+		{"range.go", "lastPair", withRange, assignments, []string{"i", "x"}},
+		{"range.go", "lastPair", withRange, assignmentContexts, []string{
+			"Range assignment (=, variable 1 of 2)", "Range assignment (=, variable 2 of 2)",
+		}},
+		{"returns.go", "clampEarly", withReturns, branches, []string{"return", "return", "return"}},
+		{"literals.go", "mapLiteral", withLiterals, assignments, []string{
+			"options", `"uppercase"`, `"lowercase"`, `"trim"`, `"prefix"`,
+		}},
+		{"literals.go", "structLiteral", withLiterals, assignments, []string{"p", "X", "Y"}},
+		{"literals.go", "sliceLiteral", withLiterals, assignments, []string{"points", "[0]", "[0]", "[1]", "[1]", "[0]", "[1]"}},
+		{"init_stmts.go", "typeSwitchInit", skipInit, assignments, []string{"x"}},
+		{"init_stmts.go", "forInitPost", skipInit, assignments, []string{"total", "total"}},
+		{"comparisons.go", "inRange", withComparisons, conditions, []string{"&&", "<=", "<="}},
+		{"comparisons.go", "inRange", withComparisons, conditionContexts, []string{"Logical operator", "Comparison", "Comparison"}},
+	})
+
+	// The guard tally is reported on its own and leaves C unchanged
+	for _, cfg := range []CountConfig{{}, {CountGuards: true}} {
+		want := 0
+		if cfg.CountGuards {
+			want = 2
+		}
+		if got := analyzeFixture(t, "guards.go", cfg).Guards; got != want {
+			t.Errorf("CountGuards=%v: got %d guards, want %d", cfg.CountGuards, got, want)
+		}
+	}
+}
+
+func TestGoNesting(t *testing.T) {
+	nesting := CountConfig{TrackNesting: true}
+	checkCounts(t, []countCase{
+		{"nesting.go", "nestedLoops", nesting, counts{2, 0, 4}},
+		{"nesting.go", "elseIfChain", nesting, counts{2, 0, 6}},
+		{"nesting.go", "switchInLoop", nesting, counts{2, 1, 5}},
+	})
+
+	checkDetails(t, []detailCase{
+		{"nesting.go", "nestedLoops", nesting, conditionContexts, []string{
+			"Condition, depth 1", "Condition, depth 2", "Condition, depth 3", "Logical operator, depth 3",
+		}},
+		{"nesting.go", "nestedLoops", CountConfig{}, conditionContexts, []string{
+			"Condition", "Condition", "Condition", "Logical operator",
+		}},
+	})
+
+	// MaxNesting is the deepest depth and is only tracked on request
+	for _, cfg := range []CountConfig{{}, nesting} {
+		want := 0
+		if cfg.TrackNesting {
+			want = 3
+		}
+		if got := analyzeFixture(t, "nesting.go", cfg).MaxNesting; got != want {
+			t.Errorf("TrackNesting=%v: got MaxNesting %d, want %d", cfg.TrackNesting, got, want)
+		}
+		for name, fn := range analyzeFunctions(t, "nesting.go", cfg) {
+			if fn.Metrics.MaxNesting != want {
+				t.Errorf("TrackNesting=%v: %s got MaxNesting %d, want %d", cfg.TrackNesting, name, fn.Metrics.MaxNesting, want)
+			}
+		}
+	}
+}
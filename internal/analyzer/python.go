@@ -0,0 +1,435 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// PythonAnalyzer implements the Analyzer interface for Python code. Like the
+// TypeScript analyzer it works on a token stream, using line structure and
+// bracket depth instead of a full syntax tree.
+type PythonAnalyzer struct{}
+
+// NewPythonAnalyzer creates a new Python analyzer
+func NewPythonAnalyzer() *PythonAnalyzer {
+	return &PythonAnalyzer{}
+}
+
+// SupportedExtensions returns the list of file extensions supported by this analyzer
+func (a *PythonAnalyzer) SupportedExtensions() []string {
+	return []string{".py"}
+}
+
+// AnalyzeFile analyzes a Python file and returns ABC metrics
+func (a *PythonAnalyzer) AnalyzeFile(filePath string) (metrics.ABCMetrics, error) {
+	// Read file content
+	content, err := readFile(filePath)
+	if err != nil {
+		return metrics.ABCMetrics{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return a.AnalyzeSource(filePath, content)
+}
+
+// AnalyzeSource analyzes Python source code and returns ABC metrics. The
+// filename is only used for position information.
+func (a *PythonAnalyzer) AnalyzeSource(filename string, src []byte) (metrics.ABCMetrics, error) {
+	c := &pyCounter{newTokenCounter(tokenizePython(src), pyChainSeparators)}
+	c.count()
+	c.metrics.Lines = countLines(src)
+
+	return c.metrics, nil
+}
+
+// punctTokenerators lists multi-character operators, longest first
+var punctTokenerators = []string{
+	"**=", "//=", ">>=", "<<=", "...", "->", ":=", "+=", "-=", "*=", "/=", "%=",
+	"&=", "|=", "^=", "@=", "**", "//", "<<", ">>", "<=", ">=", "==", "!=",
+}
+
+// pyLexer turns Python source into tokens. Comments and indentation are
+// dropped; line breaks outside brackets become newline tokens.
+type pyLexer struct {
+	lexer
+	depth int
+}
+
+// tokenizePython splits Python source into tokens. It is lenient:
+// unterminated strings simply end at the end of the input.
+func tokenizePython(src []byte) []lexToken {
+	l := &pyLexer{lexer: newLexer(src)}
+	l.run()
+	l.newline()
+	return l.tokens
+}
+
+// run tokenizes the whole input
+func (l *pyLexer) run() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		line, col := l.line, l.col
+
+		switch {
+		case c == '#':
+			l.skipLine()
+
+		case c == '\\' && (l.peek(1) == '\n' || l.peek(1) == '\r'):
+			// Explicit line joining
+			l.skipLine()
+			l.advance(1)
+
+		case c == '\n':
+			if l.depth == 0 {
+				l.newline()
+			}
+			l.advance(1)
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			l.advance(1)
+
+		case l.stringPrefixLen() >= 0:
+			start := l.pos
+			l.advance(l.stringPrefixLen())
+			l.scanString()
+			l.emit(stringToken, start, line, col)
+
+		case isPyIdentStart(l.src[l.pos:]):
+			start := l.pos
+			l.scanIdent(isPyIdentPart)
+			l.emit(identToken, start, line, col)
+
+		case c >= '0' && c <= '9' || c == '.' && l.peek(1) >= '0' && l.peek(1) <= '9':
+			start := l.pos
+			for l.pos < len(l.src) {
+				b := l.src[l.pos]
+				exponentSign := (b == '+' || b == '-') && (l.src[l.pos-1] == 'e' || l.src[l.pos-1] == 'E') &&
+					!bytes.ContainsAny(l.src[start:l.pos], "xX")
+				if !isPyIdentPart(l.src[l.pos:]) && b != '.' && !exponentSign {
+					break
+				}
+				l.advance(1)
+			}
+			l.emit(numberToken, start, line, col)
+
+		default:
+			op := l.matchPunct(punctTokenerators)
+			switch op {
+			case "(", "[", "{":
+				l.depth++
+			case ")", "]", "}":
+				if l.depth > 0 {
+					l.depth--
+				}
+			}
+			l.punct(op, line, col)
+		}
+	}
+}
+
+// stringPrefixLen returns the length of the string prefix (such as r, b or
+// f) before a quote at the current position, or -1 when no string starts here
+func (l *pyLexer) stringPrefixLen() int {
+	for n := 0; n <= 2; n++ {
+		c := l.peek(n)
+		if c == '"' || c == '\'' {
+			return n
+		}
+		if !strings.ContainsRune("rRbBuUfF", rune(c)) || c == 0 {
+			return -1
+		}
+	}
+	return -1
+}
+
+// scanString consumes a quoted or triple-quoted string starting at the
+// current position. Single-quoted strings end at the end of the line.
+func (l *pyLexer) scanString() {
+	quote := l.src[l.pos]
+	if l.peek(1) == quote && l.peek(2) == quote {
+		l.advance(3)
+		for l.pos < len(l.src) && !(l.src[l.pos] == quote && l.peek(1) == quote && l.peek(2) == quote) {
+			if l.src[l.pos] == '\\' {
+				l.advance(1)
+			}
+			l.advance(1)
+		}
+		l.advance(3)
+		return
+	}
+
+	l.advance(1)
+	for l.pos < len(l.src) && l.src[l.pos] != quote && l.src[l.pos] != '\n' {
+		if l.src[l.pos] == '\\' {
+			l.advance(1)
+		}
+		l.advance(1)
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == quote {
+		l.advance(1)
+	}
+}
+
+// newline ends the current logical line, unless it is empty
+func (l *pyLexer) newline() {
+	if len(l.tokens) == 0 || l.tokens[len(l.tokens)-1].kind == newlineToken {
+		return
+	}
+	l.tokens = append(l.tokens, lexToken{kind: newlineToken, line: l.line, col: l.col})
+}
+
+// isPyIdentStart reports whether the input starts with an identifier character
+func isPyIdentStart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isPyIdentPart reports whether the input starts with a character allowed
+// inside an identifier
+func isPyIdentPart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// pyLoopKeywords are the keywords that count as conditions in statements and
+// comprehensions
+var pyLoopKeywords = map[string]bool{
+	"if": true, "elif": true, "for": true, "while": true,
+}
+
+// pyCompoundKeywords start statements whose header ends with a colon, after
+// which a simple statement may follow on the same line
+var pyCompoundKeywords = map[string]bool{
+	"if": true, "elif": true, "else": true, "for": true, "while": true, "try": true,
+	"except": true, "finally": true, "with": true, "def": true, "class": true,
+	"async": true, "match": true, "case": true,
+}
+
+// pyNonCallKeywords are keywords that may be followed by a parenthesis
+// without being a function call
+var pyNonCallKeywords = map[string]bool{
+	"if": true, "elif": true, "while": true, "for": true, "and": true, "or": true,
+	"not": true, "in": true, "is": true, "return": true, "yield": true, "assert": true,
+	"del": true, "lambda": true, "await": true, "with": true, "except": true,
+	"raise": true, "import": true, "from": true, "as": true, "else": true,
+	"global": true, "nonlocal": true, "match": true, "case": true,
+}
+
+// pyAugmentedOperators are the augmented assignment operators
+var pyAugmentedOperators = map[string]bool{
+	"+=": true, "-=": true, "*=": true, "/=": true, "//=": true, "%=": true, "**=": true,
+	">>=": true, "<<=": true, "&=": true, "|=": true, "^=": true, "@=": true,
+}
+
+// pyChainSeparators join the names of an attribute chain
+var pyChainSeparators = map[string]string{".": "."}
+
+// pyCounter walks a token stream and collects ABC metrics
+type pyCounter struct {
+	tokenCounter
+}
+
+// count classifies every token of the stream
+func (c *pyCounter) count() {
+	depth := 0
+	stmtStart := 0  // First token of the current simple statement
+	assignFrom := 0 // First token of the current assignment target list
+
+	for i, t := range c.tokens {
+		switch t.kind {
+		case newlineToken:
+			stmtStart, assignFrom = i+1, i+1
+
+		case punctToken:
+			switch {
+			case t.text == "(" || t.text == "[" || t.text == "{":
+				depth++
+			case t.text == ")" || t.text == "]" || t.text == "}":
+				depth--
+			case t.text == ";" && depth == 0:
+				stmtStart, assignFrom = i+1, i+1
+			case t.text == ":" && depth == 0 && pyCompoundKeywords[c.textAt(stmtStart)]:
+				// A simple statement may follow a compound statement header
+				stmtStart, assignFrom = i+1, i+1
+
+			case t.text == "=" && depth == 0 && !c.inLambda(stmtStart, i):
+				c.addTargets(c.targets(assignFrom, i), "Assignment", t.text)
+				assignFrom = i + 1
+			case pyAugmentedOperators[t.text]:
+				c.addTargets(c.targets(assignFrom, i), "Compound assignment", t.text)
+			case t.text == ":=":
+				// The target is the name right before the operator, if any
+				targets := []pyTarget{{"expr", t}}
+				if i > stmtStart {
+					targets = c.targets(i-1, i)
+				}
+				c.addTargets(targets, "Assignment expression", t.text)
+			}
+
+		case identToken:
+			// Attribute names following a dot are never keywords
+			if c.textAt(i-1) == "." {
+				if c.textAt(i+1) == "(" {
+					c.addBranch(t, c.chainName(i))
+				}
+				break
+			}
+
+			atStart := i == stmtStart || i == stmtStart+1 && c.textAt(stmtStart) == "async"
+			switch {
+			case pyLoopKeywords[t.text] && atStart:
+				c.addCondition(t, t.text+" statement")
+			case t.text == "for":
+				c.addCondition(t, "for clause")
+			case t.text == "if":
+				c.addCondition(t, "if expression")
+			case t.text == "and" || t.text == "or":
+				c.addLogicalOperator(t)
+			case t.text == "except":
+				c.addCondition(t, "except clause")
+			case t.text == "else" && c.textAt(i+1) == ":":
+				c.addCondition(t, "else branch")
+			case (t.text == "match" || t.text == "case") && atStart && c.isSoftKeyword(i):
+				// A bare wildcard case is the default, like default in a Go switch
+				if t.text == "match" {
+					c.addCondition(t, "match statement")
+				} else if c.textAt(i+1) != "_" || c.textAt(i+2) != ":" {
+					c.addCondition(t, "case clause")
+				}
+			case c.textAt(i+1) == "(" && !pyNonCallKeywords[t.text] && c.textAt(i-1) != "def" && c.textAt(i-1) != "class":
+				c.addBranch(t, c.chainName(i))
+			}
+		}
+	}
+}
+
+// isSoftKeyword reports whether match or case at i starts a match statement
+// or case block rather than being used as an ordinary name. Such a line ends
+// with a colon and the name is not assigned to, called or accessed.
+func (c *pyCounter) isSoftKeyword(i int) bool {
+	switch c.textAt(i + 1) {
+	case "=", ".", ":", ",", ")", "":
+		return false
+	}
+
+	end := i
+	for end < len(c.tokens) && c.tokens[end].kind != newlineToken {
+		end++
+	}
+	return c.textAt(end-1) == ":"
+}
+
+// inLambda reports whether a lambda keyword appears between from and to, in
+// which case an = is a parameter default rather than an assignment
+func (c *pyCounter) inLambda(from, to int) bool {
+	for j := from; j < to; j++ {
+		if c.tokens[j].kind == identToken && c.tokens[j].text == "lambda" {
+			return true
+		}
+	}
+	return false
+}
+
+// pyTarget is an assignment target and the token it starts at
+type pyTarget struct {
+	name string
+	at   lexToken
+}
+
+// targets names the comma-separated assignment targets between from and to,
+// the assignment operator. Each target is an attribute chain such as
+// self.count, optionally with a type annotation; anything else, such as an
+// index expression, is "expr". Parenthesized and bracketed target lists are
+// unpacked.
+func (c *pyCounter) targets(from, to int) []pyTarget {
+	var names []pyTarget
+	depth := 0
+	start := from
+	for j := from; j <= to; j++ {
+		if j < to {
+			switch c.tokens[j].text {
+			case "(", "[", "{":
+				depth++
+				continue
+			case ")", "]", "}":
+				depth--
+				continue
+			case ",":
+				if depth != 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+
+		if start < j {
+			names = append(names, c.targetNames(start, j)...)
+		}
+		start = j + 1
+	}
+
+	if len(names) == 0 {
+		return []pyTarget{{"expr", c.tokens[to]}}
+	}
+	return names
+}
+
+// targetNames names a single assignment target between from and to
+func (c *pyCounter) targetNames(from, to int) []pyTarget {
+	if c.textAt(from) == "*" {
+		from++
+	}
+
+	// Unpack (a, b) and [a, b]
+	open := c.textAt(from)
+	if (open == "(" || open == "[") && c.matchingBracket(from) == to-1 {
+		return c.targets(from+1, to-1)
+	}
+
+	if c.kindAt(from) != identToken {
+		return []pyTarget{{"expr", c.tokens[from]}}
+	}
+
+	name := c.tokens[from].text
+	j := from + 1
+	for ; c.textAt(j) == "." && c.kindAt(j+1) == identToken && j+1 < to; j += 2 {
+		name += "." + c.tokens[j+1].text
+	}
+
+	// Only a type annotation may follow the name
+	if j < to && c.textAt(j) != ":" {
+		return []pyTarget{{"expr", c.tokens[from]}}
+	}
+	return []pyTarget{{name, c.tokens[from]}}
+}
+
+// matchingBracket returns the index of the bracket closing the one at open
+func (c *pyCounter) matchingBracket(open int) int {
+	depth := 0
+	for j := open; j < len(c.tokens); j++ {
+		switch c.tokens[j].text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(c.tokens)
+}
+
+// addTargets records every target of an assignment as a detail of its own,
+// positioned at the target, like the Go analyzer does
+func (c *pyCounter) addTargets(targets []pyTarget, kind, operator string) {
+	for i, target := range targets {
+		context := fmt.Sprintf("%s (%s%s)", kind, operator, variableOf(i, len(targets)))
+		c.addAssignment(target.at, target.name, context)
+	}
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+func TestPythonAssignmentExpressions(t *testing.T) {
+	checkSources(t, NewPythonAnalyzer(), "walrus.py", []sourceCase{
+		{"named target", "if (n := len(items)) > 10:\n    pass\n", countsAndDetails{
+			counts:      counts{1, 1, 1},
+			assignments: []string{"n"},
+			branches:    []string{"len"},
+			conditions:  []string{"if statement"},
+		}},
+		{"operator first in the file", ":= 1\n", countsAndDetails{
+			counts:      counts{1, 0, 0},
+			assignments: []string{"expr"},
+		}},
+		{"operator first in a statement", "x = 1\n:= 2\n", countsAndDetails{
+			counts:      counts{2, 0, 0},
+			assignments: []string{"x", "expr"},
+		}},
+	})
+}
+
+func TestPythonFixture(t *testing.T) {
+	checkCounts(t, []countCase{
+		{"counting.py", "", CountConfig{}, counts{13, 7, 14}},
+	})
+
+	checkDetails(t, []detailCase{
+		{"counting.py", "", CountConfig{}, assignments, []string{
+			"limit", "self.items", "expr", "expr", "total", "count", "first", "rest", "count", "n", "data", "data", "label",
+		}},
+		{"counting.py", "", CountConfig{}, assignmentContexts, []string{
+			"Assignment (=)", "Assignment (=)", "Compound assignment (+=)", "Assignment (=)", "Assignment (=)", "Assignment (=)",
+			"Assignment (=, variable 1 of 2)", "Assignment (=, variable 2 of 2)", "Compound assignment (+=)",
+			"Assignment expression (:=)", "Assignment (=)", "Assignment (=)", "Assignment (=)",
+		}},
+	})
+
+	// Every counted assignment has a detail of its own
+	m := analyzeFixture(t, "counting.py", CountConfig{})
+	if len(m.AssignmentList) != m.Assignments {
+		t.Errorf("got %d assignment details, want %d", len(m.AssignmentList), m.Assignments)
+	}
+}
+
+func TestPythonAssignments(t *testing.T) {
+	checkSources(t, NewPythonAnalyzer(), "assign.py", []sourceCase{
+		{"chained", "a = b = 1\n", countsAndDetails{
+			counts:      counts{2, 0, 0},
+			assignments: []string{"a", "b"},
+		}},
+		{"tuple", "y, z = 1, 2\n", countsAndDetails{
+			counts:      counts{2, 0, 0},
+			assignments: []string{"y", "z"},
+		}},
+		{"nested unpacking", "a, (b, c) = t\n", countsAndDetails{
+			counts:      counts{3, 0, 0},
+			assignments: []string{"a", "b", "c"},
+		}},
+		{"annotated", "x: int = 1\n", countsAndDetails{
+			counts:      counts{1, 0, 0},
+			assignments: []string{"x"},
+		}},
+		{"keyword argument", "f(a=1)\n", countsAndDetails{
+			counts:   counts{0, 1, 0},
+			branches: []string{"f"},
+		}},
+		{"lambda default", "g = lambda x=1: x\n", countsAndDetails{
+			counts:      counts{1, 0, 0},
+			assignments: []string{"g"},
+		}},
+		{"parameter defaults", "def f(a=1, *b, **c):\n    return a\n", countsAndDetails{}},
+		{"augmented operators", "x += 1\na.b //= 2\nx[i] **= 2\ny @= m\n", countsAndDetails{
+			counts:      counts{4, 0, 0},
+			assignments: []string{"x", "a.b", "expr", "y"},
+		}},
+	})
+}
+
+func TestPythonComprehensions(t *testing.T) {
+	checkSources(t, NewPythonAnalyzer(), "comprehension.py", []sourceCase{
+		{"filtered dict comprehension", "d = {k: v for k, v in items if v}\n", countsAndDetails{
+			counts:      counts{1, 0, 2},
+			assignments: []string{"d"},
+			conditions:  []string{"for clause", "if expression"},
+		}},
+		{"nested generator", "s = sum(x for row in rows for x in row)\n", countsAndDetails{
+			counts:      counts{1, 1, 2},
+			assignments: []string{"s"},
+			branches:    []string{"sum"},
+			conditions:  []string{"for clause", "for clause"},
+		}},
+		{"conditional expression", "print(x if ok else y)\n", countsAndDetails{
+			counts:     counts{0, 1, 1},
+			branches:   []string{"print"},
+			conditions: []string{"if expression"},
+		}},
+	})
+}
+
+func TestPythonAssignmentPositions(t *testing.T) {
+	m, err := NewPythonAnalyzer().AnalyzeSource("assign.py", []byte("y, z = 1, 2\na = b = 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []metrics.MetricDetail{
+		{Line: 1, Col: 1, Text: "y", Context: "Assignment (=, variable 1 of 2)"},
+		{Line: 1, Col: 4, Text: "z", Context: "Assignment (=, variable 2 of 2)"},
+		{Line: 2, Col: 1, Text: "a", Context: "Assignment (=)"},
+		{Line: 2, Col: 5, Text: "b", Context: "Assignment (=)"},
+	}
+	if !reflect.DeepEqual(m.AssignmentList, want) {
+		t.Errorf("got %+v, want %+v", m.AssignmentList, want)
+	}
+	if m.Assignments != len(m.AssignmentList) {
+		t.Errorf("Assignments = %d, want %d", m.Assignments, len(m.AssignmentList))
+	}
+}
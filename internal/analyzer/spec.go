@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultSpec is the name of the counting convention used when none is
+// selected. It enables no optional rule.
+const DefaultSpec = "default"
+
+// specs are the named counting conventions of the Go analyzer, each a set of
+// optional rules enabled on top of the default ones. Like every CountConfig,
+// they leave the results of the other languages unchanged.
+var specs = map[string]CountConfig{
+	DefaultSpec: {},
+
+	// Fitzpatrick's original definition: comparisons are conditions,
+	// every variable bound by a loop is an assignment and every return
+	// is a branch
+	"fitzpatrick": {
+		CountComparisons:      true,
+		CountRangeAssignments: true,
+		CountReturns:          true,
+	},
+
+	// Only what adds paths through the code: the init and post statements
+	// of control statements are not assignments, deferred calls are not
+	// branches and type assertions are not conditions
+	"minimal": {
+		SkipInitAssignments: true,
+		SkipDeferredCalls:   true,
+		SkipTypeAssertions:  true,
+	},
+}
+
+// SpecNames returns the names of the counting conventions in lexical order
+func SpecNames() []string {
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SpecConfig returns the counting rules of the named convention
+func SpecConfig(name string) (CountConfig, error) {
+	cfg, ok := specs[name]
+	if !ok {
+		return CountConfig{}, fmt.Errorf("unknown spec %q (expected %s)", name, strings.Join(SpecNames(), ", "))
+	}
+	return cfg, nil
+}
@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// tokenKind classifies the tokens of the token-based analyzers
+type tokenKind int
+
+const (
+	identToken   tokenKind = iota // Identifiers and keywords
+	numberToken                   // Numeric literals
+	stringToken                   // String, character, template and regular expression literals
+	punctToken                    // Operators and punctuation
+	newlineToken                  // End of a logical line, only produced for Python
+)
+
+// lexToken is a single lexical token with its position
+type lexToken struct {
+	kind tokenKind
+	text string
+	line int
+	col  int
+}
+
+// lexer holds the scanning state shared by the tokenizers of the TypeScript,
+// Python and C analyzers. Every scanning method consumes at least one byte
+// when there is input left, so the tokenizer loops always make progress.
+type lexer struct {
+	src    []byte
+	pos    int
+	line   int
+	col    int
+	tokens []lexToken
+}
+
+// newLexer creates a lexer positioned at the start of the source
+func newLexer(src []byte) lexer {
+	return lexer{src: src, line: 1, col: 1}
+}
+
+// scanIdent consumes the characters of an identifier, as told by isPart
+func (l *lexer) scanIdent(isPart func([]byte) bool) {
+	for l.pos < len(l.src) && isPart(l.src[l.pos:]) {
+		_, size := utf8.DecodeRune(l.src[l.pos:])
+		l.advance(size)
+	}
+}
+
+// skipLine consumes the rest of the current line, leaving the newline
+func (l *lexer) skipLine() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+		l.advance(1)
+	}
+}
+
+// scanQuoted consumes a single- or double-quoted literal. Literals end at an
+// unescaped quote or, for malformed input, at the end of the line.
+func (l *lexer) scanQuoted(quote byte) {
+	l.advance(1)
+	for l.pos < len(l.src) && l.src[l.pos] != quote && l.src[l.pos] != '\n' {
+		if l.src[l.pos] == '\\' {
+			l.advance(1)
+		}
+		l.advance(1)
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == quote {
+		l.advance(1)
+	}
+}
+
+// matchPunct returns the longest of the punctuators, listed longest first,
+// at the current position, or the single character there
+func (l *lexer) matchPunct(punctuators []string) string {
+	rest := l.src[l.pos:]
+	for _, p := range punctuators {
+		if bytes.HasPrefix(rest, []byte(p)) {
+			return p
+		}
+	}
+	_, size := utf8.DecodeRune(rest)
+	return string(rest[:size])
+}
+
+// punct emits a punctuation token and consumes it
+func (l *lexer) punct(text string, line, col int) {
+	l.advance(len(text))
+	l.tokens = append(l.tokens, lexToken{kind: punctToken, text: text, line: line, col: col})
+}
+
+// emit appends a token spanning from start to the current position
+func (l *lexer) emit(kind tokenKind, start, line, col int) {
+	l.tokens = append(l.tokens, lexToken{kind: kind, text: string(l.src[start:l.pos]), line: line, col: col})
+}
+
+// peek returns the byte at the given offset from the current position, or 0
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+// advance moves the position forward, keeping track of lines and columns
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.src); i++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+// tokenCounter holds the counting state shared by the TypeScript, Python and
+// C analyzers, which walk a token stream and collect ABC metrics
+type tokenCounter struct {
+	tokens  []lexToken
+	metrics metrics.ABCMetrics
+
+	// chainSeparators maps the tokens joining the names of a member chain,
+	// such as . or ->, to how chainName renders them
+	chainSeparators map[string]string
+}
+
+// newTokenCounter creates a counter with empty metrics for the tokens
+func newTokenCounter(tokens []lexToken, chainSeparators map[string]string) tokenCounter {
+	return tokenCounter{
+		tokens: tokens,
+		metrics: metrics.ABCMetrics{
+			AssignmentList: []metrics.MetricDetail{},
+			BranchList:     []metrics.MetricDetail{},
+			ConditionList:  []metrics.MetricDetail{},
+			Warnings:       []metrics.MetricDetail{},
+		},
+		chainSeparators: chainSeparators,
+	}
+}
+
+// textAt returns the text of the token at i, or "" when out of range
+func (c *tokenCounter) textAt(i int) string {
+	if i < 0 || i >= len(c.tokens) {
+		return ""
+	}
+	return c.tokens[i].text
+}
+
+// kindAt returns the kind of the token at i, or punctToken when out of range
+func (c *tokenCounter) kindAt(i int) tokenKind {
+	if i < 0 || i >= len(c.tokens) {
+		return punctToken
+	}
+	return c.tokens[i].kind
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open
+func (c *tokenCounter) matchingParen(open int) int {
+	depth := 0
+	for j := open; j < len(c.tokens); j++ {
+		if c.tokens[j].kind != punctToken {
+			continue
+		}
+		switch c.tokens[j].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(c.tokens)
+}
+
+// chainName renders the identifier at i together with the member chain
+// leading to it, such as console.log, s->ops.read or std::sort
+func (c *tokenCounter) chainName(i int) string {
+	name := c.tokens[i].text
+	for j := i; j >= 2; j -= 2 {
+		sep, ok := c.chainSeparators[c.textAt(j-1)]
+		if !ok || c.kindAt(j-2) != identToken {
+			break
+		}
+		name = c.tokens[j-2].text + sep + name
+	}
+	return name
+}
+
+// incDecTarget names the operand of the increment or decrement at i, which
+// is the preceding token for postfix and the following one for prefix form
+func (c *tokenCounter) incDecTarget(i int) string {
+	if i > 0 && c.tokens[i-1].line == c.tokens[i].line {
+		switch prev := c.tokens[i-1]; {
+		case prev.kind == identToken:
+			return c.chainName(i - 1)
+		case prev.text == "]" || prev.text == ")":
+			return "expr"
+		}
+	}
+	if c.kindAt(i+1) == identToken {
+		return c.tokens[i+1].text
+	}
+	return "expr"
+}
+
+// addAssignment records an assignment
+func (c *tokenCounter) addAssignment(t lexToken, target, context string) {
+	c.addDetail(&c.metrics.Assignments, &c.metrics.AssignmentList, t, target, context)
+}
+
+// addBranch records a function or method call
+func (c *tokenCounter) addBranch(t lexToken, name string) {
+	c.addDetail(&c.metrics.Branches, &c.metrics.BranchList, t, name, "Function call")
+}
+
+// addCondition records a condition
+func (c *tokenCounter) addCondition(t lexToken, text string) {
+	c.addDetail(&c.metrics.Conditions, &c.metrics.ConditionList, t, text, "Condition")
+}
+
+// addLogicalOperator records a logical operator as a condition
+func (c *tokenCounter) addLogicalOperator(t lexToken) {
+	c.addDetail(&c.metrics.Conditions, &c.metrics.ConditionList, t, t.text, "Logical operator")
+}
+
+// addDetail increments a counter and appends the matching detail
+func (c *tokenCounter) addDetail(counter *int, list *[]metrics.MetricDetail, t lexToken, text, context string) {
+	*counter++
+	*list = append(*list, metrics.MetricDetail{
+		Line:    t.line,
+		Col:     t.col,
+		Text:    text,
+		Context: context,
+	})
+}
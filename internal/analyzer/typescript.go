@@ -0,0 +1,422 @@
+package analyzer
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// TypeScriptAnalyzer implements the Analyzer interface for TypeScript and
+// JavaScript code. It works on a token stream rather than a full syntax
+// tree, so counting is a best-effort approximation of the Go analyzer rules.
+type TypeScriptAnalyzer struct{}
+
+// NewTypeScriptAnalyzer creates a new TypeScript/JavaScript analyzer
+func NewTypeScriptAnalyzer() *TypeScriptAnalyzer {
+	return &TypeScriptAnalyzer{}
+}
+
+// SupportedExtensions returns the list of file extensions supported by this analyzer
+func (a *TypeScriptAnalyzer) SupportedExtensions() []string {
+	return []string{".ts", ".tsx", ".js", ".jsx"}
+}
+
+// AnalyzeFile analyzes a TypeScript or JavaScript file and returns ABC metrics
+func (a *TypeScriptAnalyzer) AnalyzeFile(filePath string) (metrics.ABCMetrics, error) {
+	// Read file content
+	content, err := readFile(filePath)
+	if err != nil {
+		return metrics.ABCMetrics{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return a.AnalyzeSource(filePath, content)
+}
+
+// AnalyzeSource analyzes TypeScript or JavaScript source code and returns ABC
+// metrics. The filename is only used for position information.
+func (a *TypeScriptAnalyzer) AnalyzeSource(filename string, src []byte) (metrics.ABCMetrics, error) {
+	c := &jsCounter{newTokenCounter(tokenizeJS(src), jsChainSeparators)}
+	c.count()
+	c.metrics.Lines = countLines(src)
+
+	return c.metrics, nil
+}
+
+// jsPunctuators lists multi-character punctuators, longest first
+var jsPunctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=",
+	"*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+// jsRegexKeywords are keywords after which a slash starts a regular expression
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true,
+	"of": true, "instanceof": true, "new": true, "delete": true, "void": true,
+	"throw": true, "yield": true, "await": true,
+}
+
+// jsLexer turns JavaScript/TypeScript source into tokens. Comments are
+// dropped, and template literal substitutions are tokenized like regular
+// code between "${" and "}" tokens.
+type jsLexer struct {
+	lexer
+
+	// templates holds the open brace depth of every template substitution
+	// being tokenized, innermost last
+	templates []int
+}
+
+// tokenizeJS splits JavaScript/TypeScript source into tokens. It is lenient:
+// unterminated literals and comments simply end at the end of the input.
+func tokenizeJS(src []byte) []lexToken {
+	l := &jsLexer{lexer: newLexer(src)}
+	l.run()
+	return l.tokens
+}
+
+// run tokenizes the whole input
+func (l *jsLexer) run() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		line, col := l.line, l.col
+
+		switch {
+		case c == '\n' || c == ' ' || c == '\t' || c == '\r':
+			l.advance(1)
+
+		case c == '#' && l.peek(1) == '!' && l.pos == 0:
+			// The hashbang line of an executable script
+			l.skipLine()
+
+		case c == '#' && isJSIdentStart(l.src[l.pos+1:]):
+			// Private class members such as #count are a single name
+			start := l.pos
+			l.advance(1)
+			l.scanIdent(isJSIdentPart)
+			l.emit(identToken, start, line, col)
+
+		case c == '/' && l.peek(1) == '/':
+			l.skipLine()
+
+		case c == '/' && l.peek(1) == '*':
+			l.advance(2)
+			for l.pos < len(l.src) && !(l.src[l.pos] == '*' && l.peek(1) == '/') {
+				l.advance(1)
+			}
+			l.advance(2)
+
+		case c == '"' || c == '\'':
+			start := l.pos
+			l.scanQuoted(c)
+			l.emit(stringToken, start, line, col)
+
+		case c == '`':
+			l.advance(1)
+			l.scanTemplate(line, col)
+
+		case c == '/' && l.regexAllowed():
+			start := l.pos
+			l.scanRegex()
+			l.emit(stringToken, start, line, col)
+
+		case isJSIdentStart(l.src[l.pos:]):
+			start := l.pos
+			l.scanIdent(isJSIdentPart)
+			l.emit(identToken, start, line, col)
+
+		case c >= '0' && c <= '9' || c == '.' && l.peek(1) >= '0' && l.peek(1) <= '9':
+			start := l.pos
+			for l.pos < len(l.src) && (isJSIdentPart(l.src[l.pos:]) || l.src[l.pos] == '.') {
+				l.advance(1)
+			}
+			l.emit(numberToken, start, line, col)
+
+		case c == '{' && len(l.templates) > 0:
+			l.templates[len(l.templates)-1]++
+			l.punct("{", line, col)
+
+		case c == '}' && len(l.templates) > 0 && l.templates[len(l.templates)-1] == 0:
+			// End of a template substitution, continue with the template text
+			l.templates = l.templates[:len(l.templates)-1]
+			l.punct("}", line, col)
+			l.scanTemplate(line, col)
+
+		case c == '}' && len(l.templates) > 0:
+			l.templates[len(l.templates)-1]--
+			l.punct("}", line, col)
+
+		default:
+			l.punct(l.matchPunct(), line, col)
+		}
+	}
+}
+
+// scanTemplate consumes template literal text up to the closing backtick or
+// the start of a substitution. The opening backtick or closing brace has
+// already been consumed.
+func (l *jsLexer) scanTemplate(line, col int) {
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == '\\':
+			l.advance(2)
+		case l.src[l.pos] == '`':
+			l.advance(1)
+			l.tokens = append(l.tokens, lexToken{kind: stringToken, text: "`template`", line: line, col: col})
+			return
+		case l.src[l.pos] == '$' && l.peek(1) == '{':
+			l.punct("${", l.line, l.col)
+			l.templates = append(l.templates, 0)
+			return
+		default:
+			l.advance(1)
+		}
+	}
+}
+
+// scanRegex consumes a regular expression literal including its flags
+func (l *jsLexer) scanRegex() {
+	l.advance(1)
+	inClass := false
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+		c := l.src[l.pos]
+		if c == '\\' {
+			l.advance(2)
+			continue
+		}
+		l.advance(1)
+		if c == '[' {
+			inClass = true
+		} else if c == ']' {
+			inClass = false
+		} else if c == '/' && !inClass {
+			break
+		}
+	}
+	for l.pos < len(l.src) && isJSIdentPart(l.src[l.pos:]) {
+		l.advance(1)
+	}
+}
+
+// regexAllowed reports whether a slash at the current position starts a
+// regular expression rather than a division, based on the previous token
+func (l *jsLexer) regexAllowed() bool {
+	if len(l.tokens) == 0 {
+		return true
+	}
+
+	prev := l.tokens[len(l.tokens)-1]
+	switch prev.kind {
+	case identToken:
+		return jsRegexKeywords[prev.text]
+	case numberToken, stringToken:
+		return false
+	}
+	return prev.text != ")" && prev.text != "]" && prev.text != "}"
+}
+
+// matchPunct returns the longest punctuator at the current position
+func (l *jsLexer) matchPunct() string {
+	p := l.lexer.matchPunct(jsPunctuators)
+	// a?.5:1 is a conditional, not optional chaining
+	if p == "?." && l.peek(2) >= '0' && l.peek(2) <= '9' {
+		return "?"
+	}
+	return p
+}
+
+// isJSIdentStart reports whether the input starts with an identifier character
+func isJSIdentStart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// isJSIdentPart reports whether the input starts with a character allowed
+// inside an identifier
+func isJSIdentPart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// jsConditionKeywords are the keywords that count as conditions
+var jsConditionKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "case": true,
+}
+
+// jsNonCallKeywords are keywords that may be followed by a parenthesis
+// without being a function call
+var jsNonCallKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"function": true, "return": true, "typeof": true, "void": true, "delete": true,
+	"await": true, "in": true, "of": true, "instanceof": true, "new": true,
+	"with": true, "yield": true, "throw": true, "case": true, "else": true, "do": true,
+}
+
+// jsModifiers are keywords that may precede a method declaration
+var jsModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "static": true, "async": true,
+	"get": true, "set": true, "readonly": true, "abstract": true, "override": true,
+	"declare": true, "export": true, "default": true,
+}
+
+// jsDeclarationKeywords are keywords that may start the left-hand side of an
+// assignment without being its target
+var jsDeclarationKeywords = map[string]bool{
+	"const": true, "let": true, "var": true, "public": true, "private": true,
+	"protected": true, "static": true, "readonly": true, "declare": true, "export": true,
+}
+
+// jsAssignmentOperators are the operators that count as assignments
+var jsAssignmentOperators = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true, "**=": true,
+	"<<=": true, ">>=": true, ">>>=": true, "&=": true, "|=": true, "^=": true,
+	"&&=": true, "||=": true, "??=": true,
+}
+
+// jsChainSeparators join the names of a member chain, rendering optional
+// chaining like plain member access
+var jsChainSeparators = map[string]string{".": ".", "?.": "."}
+
+// jsCounter walks a token stream and collects ABC metrics
+type jsCounter struct {
+	tokenCounter
+}
+
+// count classifies every token of the stream
+func (c *jsCounter) count() {
+	typeAlias := false
+
+	for i, t := range c.tokens {
+		switch t.kind {
+		case identToken:
+			// Property names following a dot are never keywords
+			member := c.isMemberName(i)
+
+			// type Name = ... declares an alias, its = is not an assignment
+			if !member && t.text == "type" && c.kindAt(i+1) == identToken {
+				typeAlias = true
+			}
+
+			switch {
+			case !member && jsConditionKeywords[t.text]:
+				c.addCondition(t, t.text+" statement")
+			case !member && t.text == "else" && c.textAt(i+1) != "if":
+				c.addCondition(t, "else branch")
+			case c.textAt(i+1) == "(" && (member || !jsNonCallKeywords[t.text] && !c.isDeclaration(i)):
+				c.addBranch(t, c.chainName(i))
+			}
+
+		case punctToken:
+			switch {
+			case jsAssignmentOperators[t.text]:
+				if t.text == "=" && typeAlias {
+					typeAlias = false
+					break
+				}
+				context := "Assignment (=)"
+				if t.text != "=" {
+					context = "Compound assignment (" + t.text + ")"
+				}
+				c.addAssignment(t, c.targetName(i), context)
+
+			case t.text == "++" || t.text == "--":
+				context := "Increment"
+				if t.text == "--" {
+					context = "Decrement"
+				}
+				c.addAssignment(t, c.incDecTarget(i), context)
+
+			case t.text == "&&" || t.text == "||":
+				c.addLogicalOperator(t)
+
+			case t.text == "?" && !c.isOptionalMarker(i):
+				c.addCondition(t, "ternary operator")
+			}
+		}
+	}
+}
+
+// isMemberName reports whether the identifier at i is a property name
+// following a dot, which is never a keyword
+func (c *jsCounter) isMemberName(i int) bool {
+	prev := c.textAt(i - 1)
+	return prev == "." || prev == "?."
+}
+
+// isDeclaration reports whether the identifier at i, followed by an opening
+// parenthesis, declares a function or method instead of calling it
+func (c *jsCounter) isDeclaration(i int) bool {
+	prev := c.textAt(i - 1)
+	if prev == "function" {
+		return true
+	}
+
+	// Method declarations start a member or statement and their parameter
+	// list is followed by a body or a return type annotation
+	if i > 0 && !jsModifiers[prev] && prev != "{" && prev != "}" && prev != ";" && prev != "," && prev != "*" {
+		return false
+	}
+
+	end := c.matchingParen(i + 1)
+	next := c.textAt(end + 1)
+	return next == "{" || next == ":"
+}
+
+// isOptionalMarker reports whether the question mark at i marks an optional
+// property or parameter (name?: T) rather than a conditional expression
+func (c *jsCounter) isOptionalMarker(i int) bool {
+	switch c.textAt(i + 1) {
+	case ":", ")", ",", "=":
+		return true
+	}
+	return false
+}
+
+// targetName names the target of the assignment operator at i: the member
+// chain starting at the first identifier of the left-hand side, skipping
+// declaration keywords and type annotations such as const total: number = 0
+func (c *jsCounter) targetName(i int) string {
+	// Walk back to the start of the left-hand side
+	start := i
+	depth := 0
+scan:
+	for j := i - 1; j >= 0; j-- {
+		t := c.tokens[j]
+		if depth == 0 && t.line < c.tokens[i].line {
+			break
+		}
+		if t.kind == punctToken {
+			switch t.text {
+			case ")", "]", ">":
+				depth++
+			case ">>":
+				depth += 2
+			case "(", "[", "<":
+				if depth == 0 {
+					break scan
+				}
+				depth--
+			case ",", ";", "{", "}", "=", "=>", "?", ":":
+				if depth == 0 && t.text != ":" {
+					break scan
+				}
+			}
+		}
+		start = j
+	}
+
+	for k := start; k < i; k++ {
+		if c.tokens[k].kind != identToken || jsDeclarationKeywords[c.tokens[k].text] {
+			continue
+		}
+
+		name := c.tokens[k].text
+		for ; c.textAt(k+1) == "." && c.kindAt(k+2) == identToken && k+2 < i; k += 2 {
+			name += "." + c.tokens[k+2].text
+		}
+		return name
+	}
+	return "expr"
+}
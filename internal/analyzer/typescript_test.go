@@ -0,0 +1,21 @@
+package analyzer
+
+import "testing"
+
+func TestTypeScriptHashTokens(t *testing.T) {
+	checkSources(t, NewTypeScriptAnalyzer(), "hash.js", []sourceCase{
+		{"hashbang line", "#!/usr/bin/env node\nrun(args)\n", countsAndDetails{
+			counts:   counts{0, 1, 0},
+			branches: []string{"run"},
+		}},
+		{"private class members", "class C {\n  #count = 0;\n  inc() { this.#count++; this.#log() }\n}\n", countsAndDetails{
+			counts:      counts{2, 1, 0},
+			assignments: []string{"#count", "this.#count"},
+			branches:    []string{"this.#log"},
+		}},
+		{"stray hash", "x = a # b\n", countsAndDetails{
+			counts:      counts{1, 0, 0},
+			assignments: []string{"x"},
+		}},
+	})
+}
@@ -0,0 +1,102 @@
+// Package cache stores analysis results on disk, keyed by file path and
+// content hash, so unchanged files are not analyzed again on the next run.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/abc-metrics/abc/internal/report"
+)
+
+// entry is the on-disk representation of a cached result
+type entry struct {
+	Hash   string            `json:"hash"`   // SHA-256 of the file content the result was computed from
+	Result report.FileResult `json:"result"` // Analysis result of the file
+}
+
+// Cache is a directory of cached results. Each file has a single entry per
+// key, overwritten when its content changes, so the cache does not grow with
+// every edit. It is safe for concurrent use.
+type Cache struct {
+	dir string
+	key string
+}
+
+// Open returns the cache stored in dir, creating the directory if needed.
+// The key identifies everything besides the file content that the results
+// depend on, such as the analyzer version and counting rules; entries
+// written under a different key are never returned.
+func Open(dir, key string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, key: key}, nil
+}
+
+// DefaultDir returns the cache directory inside the user's cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "abc"), nil
+}
+
+// Get returns the cached result of the file at path if it was computed from
+// the same content
+func (c *Cache) Get(path string, content []byte) (report.FileResult, bool) {
+	data, err := os.ReadFile(c.entryPath(path))
+	if err != nil {
+		return report.FileResult{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Hash != ContentHash(content) {
+		return report.FileResult{}, false
+	}
+	return e.Result, true
+}
+
+// Put stores the result of the file at path computed from content. The
+// entry is written to a temporary file first and renamed into place, so
+// concurrent runs never see a partial entry.
+func (c *Cache) Put(path string, content []byte, result report.FileResult) error {
+	data, err := json.Marshal(entry{Hash: ContentHash(content), Result: result})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.entryPath(path))
+}
+
+// entryPath returns the path of the entry for the file under the cache key
+func (c *Cache) entryPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(c.key + "\x00" + path))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// ContentHash returns the hex-encoded SHA-256 of the content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
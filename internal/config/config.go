@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the config file looked up in the current directory when no
+// path is given explicitly
+const DefaultFile = ".abc.yaml"
+
+// Config holds default values for command-line flags. Pointer fields are nil
+// when the file does not set them, so an explicit false or zero in the file
+// can be told apart from a missing key.
+type Config struct {
+	Verbose   *bool    `yaml:"verbose"`   // Default for --verbose
+	Format    string   `yaml:"format"`    // Default for --format
+	Threshold *float64 `yaml:"threshold"` // Default for --threshold
+	Ignore    []string `yaml:"ignore"`    // Patterns of paths skipped while walking directories
+	Severity  Severity `yaml:"severity"`  // Defaults for the --severity-* cutoffs
+	Weights   Weights  `yaml:"weights"`   // Defaults for the --weight-* score weights
+}
+
+// Severity holds optional severity cutoffs, nil when not set in the file
+type Severity struct {
+	Low    *float64 `yaml:"low"`    // Default for --severity-low
+	Medium *float64 `yaml:"medium"` // Default for --severity-medium
+	High   *float64 `yaml:"high"`   // Default for --severity-high
+}
+
+// Weights holds optional score weights, nil when not set in the file
+type Weights struct {
+	A *float64 `yaml:"a"` // Default for --weight-a
+	B *float64 `yaml:"b"` // Default for --weight-b
+	C *float64 `yaml:"c"` // Default for --weight-c
+}
+
+// Load reads the config file at path. When path is empty, DefaultFile is used
+// if it exists and an empty config is returned otherwise.
+func Load(path string) (Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("error reading config: %w", err)
+	}
+
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("error decoding config %s: %w", path, err)
+	}
+	return cfg, nil
+}
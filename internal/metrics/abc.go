@@ -3,35 +3,195 @@ package metrics
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 )
 
 // MetricDetail represents a single item that contributes to a metric
 type MetricDetail struct {
-	Line    int    // Line number
-	Col     int    // Column number
-	Text    string // Short description or snippet
-	Context string // Additional context
+	Line    int    `json:"line"`            // Line number
+	Col     int    `json:"col"`             // Column number
+	Text    string `json:"text"`            // Short description or snippet
+	Context string `json:"context"`         // Additional context
+	Count   int    `json:"count,omitempty"` // Number of items counted, only set when more than one
+}
+
+// Weight returns the number of items the detail accounts for, such as the
+// variables of a multi-variable assignment
+func (d MetricDetail) Weight() int {
+	if d.Count > 1 {
+		return d.Count
+	}
+	return 1
 }
 
 // ABCMetrics represents the Assignment, Branch, and Condition metrics
 type ABCMetrics struct {
-	Assignments    int            // Number of assignments
-	Branches       int            // Number of branches (function calls, method calls)
-	Conditions     int            // Number of conditions (if, else, switch, case, for, while, etc.)
-	AssignmentList []MetricDetail // Details of assignments
-	BranchList     []MetricDetail // Details of branches
-	ConditionList  []MetricDetail // Details of conditions
+	Assignments    int            `json:"assignments"`    // Number of assignments
+	Branches       int            `json:"branches"`       // Number of branches (function calls, method calls)
+	Conditions     int            `json:"conditions"`     // Number of conditions (if, else, switch, case, for, while, etc.)
+	AssignmentList []MetricDetail `json:"assignmentList"` // Details of assignments
+	BranchList     []MetricDetail `json:"branchList"`     // Details of branches
+	ConditionList  []MetricDetail `json:"conditionList"`  // Details of conditions
+	Guards         int            `json:"guards"`         // Number of if statements that are early-return guards (already included in Conditions)
+	MaxNesting     int            `json:"maxNesting"`     // Deepest nesting depth of a condition, only tracked on request
+	Lines          int            `json:"lines"`          // Number of source lines analyzed
+	Warnings       []MetricDetail `json:"warnings"`       // Lint warnings; these never affect the score
+	Partial        bool           `json:"partial"`        // The source had syntax errors and only its parseable portion was analyzed
+}
+
+// FilterLines returns the metrics restricted to the details on lines for
+// which keep returns true, with the counts recomputed from those details.
+// Guards and MaxNesting are not tracked per line and are dropped; Lines is
+// unchanged.
+func (m ABCMetrics) FilterLines(keep func(line int) bool) ABCMetrics {
+	filtered := ABCMetrics{
+		AssignmentList: filterDetails(m.AssignmentList, keep),
+		BranchList:     filterDetails(m.BranchList, keep),
+		ConditionList:  filterDetails(m.ConditionList, keep),
+		Warnings:       filterDetails(m.Warnings, keep),
+		Lines:          m.Lines,
+		Partial:        m.Partial,
+	}
+	filtered.Assignments = totalWeight(filtered.AssignmentList)
+	filtered.Branches = totalWeight(filtered.BranchList)
+	filtered.Conditions = totalWeight(filtered.ConditionList)
+	return filtered
+}
+
+// filterDetails returns the details on lines for which keep returns true
+func filterDetails(details []MetricDetail, keep func(line int) bool) []MetricDetail {
+	filtered := []MetricDetail{}
+	for _, d := range details {
+		if keep(d.Line) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// totalWeight sums the weights of the details
+func totalWeight(details []MetricDetail) int {
+	total := 0
+	for _, d := range details {
+		total += d.Weight()
+	}
+	return total
+}
+
+// SortDetails returns a copy of the details sorted by line, then column. The
+// analyzers record details in walk order, which interleaves nested nodes.
+func SortDetails(details []MetricDetail) []MetricDetail {
+	sorted := append([]MetricDetail(nil), details...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Line != sorted[j].Line {
+			return sorted[i].Line < sorted[j].Line
+		}
+		return sorted[i].Col < sorted[j].Col
+	})
+	return sorted
+}
+
+// FunctionMetrics holds the ABC metrics of a single function or method
+type FunctionMetrics struct {
+	Name      string     `json:"name"`      // Function name, methods include their receiver type
+	StartLine int        `json:"startLine"` // Line of the func keyword
+	EndLine   int        `json:"endLine"`   // Line of the closing brace
+	Metrics   ABCMetrics `json:"metrics"`   // Metrics of the function body
 }
 
 // Score calculates the ABC score as sqrt(A² + B² + C²)
 func (m ABCMetrics) Score() float64 {
-	return math.Sqrt(float64(m.Assignments*m.Assignments + m.Branches*m.Branches + m.Conditions*m.Conditions))
+	return m.ScoreWith(DefaultWeights)
+}
+
+// ScoreWith calculates the ABC score with each squared term scaled by its
+// weight, as sqrt(wA·A² + wB·B² + wC·C²)
+func (m ABCMetrics) ScoreWith(w Weights) float64 {
+	a, b, c := float64(m.Assignments), float64(m.Branches), float64(m.Conditions)
+	return math.Sqrt(w.A*a*a + w.B*b*b + w.C*c*c)
+}
+
+// UniqueBranches returns the number of distinct callees in the branch list,
+// a fan-out figure telling code that calls many different functions apart
+// from code calling the same helper repeatedly. Callees are told apart by
+// the text of their details. Only function calls with a named callee count:
+// returns counted as branches and calls of function literals or of callees
+// that cannot be named are left out. Without a branch list the count is 0
+// although it is unknown; see UniqueBranchesKnown.
+func (m ABCMetrics) UniqueBranches() int {
+	callees := make(map[string]bool, len(m.BranchList))
+	for _, d := range m.BranchList {
+		if d.Context != "Function call" || anonymousCallees[d.Text] {
+			continue
+		}
+		callees[d.Text] = true
+	}
+	return len(callees)
+}
+
+// UniqueBranchesKnown reports whether UniqueBranches is known. It is not for
+// metrics with branches but no branch list, as for metrics read back from a
+// report without details.
+func (m ABCMetrics) UniqueBranchesKnown() bool {
+	return m.Branches == 0 || len(m.BranchList) > 0
+}
+
+// anonymousCallees are the detail texts of calls whose callee has no name
+var anonymousCallees = map[string]bool{"func literal": true, "unknown": true}
+
+// DecisionDensity returns the number of conditions per 100 lines of code,
+// a size-normalized view of how much of the code is branching logic
+func (m ABCMetrics) DecisionDensity() float64 {
+	if m.Lines == 0 {
+		return 0
+	}
+	return float64(m.Conditions) * 100 / float64(m.Lines)
+}
+
+// ScoreDensity returns the ABC score per 100 lines of code, telling small
+// but dense code apart from long but simple code with the same score
+func (m ABCMetrics) ScoreDensity() float64 {
+	return m.ScoreDensityWith(DefaultWeights)
+}
+
+// ScoreDensityWith returns the ABC score calculated with the given weights
+// per 100 lines of code
+func (m ABCMetrics) ScoreDensityWith(w Weights) float64 {
+	if m.Lines == 0 {
+		return 0
+	}
+	return m.ScoreWith(w) * 100 / float64(m.Lines)
 }
 
 // String returns a string representation of the ABC metrics
 func (m ABCMetrics) String() string {
+	return m.StringWith(DefaultWeights)
+}
+
+// StringWith returns a string representation of the ABC metrics with the
+// score calculated using the given weights
+func (m ABCMetrics) StringWith(w Weights) string {
 	return fmt.Sprintf("ABC: %.2f (A=%d, B=%d, C=%d)",
-		m.Score(), m.Assignments, m.Branches, m.Conditions)
+		m.ScoreWith(w), m.Assignments, m.Branches, m.Conditions)
+}
+
+// FormulaWith returns the derivation of the score with the given weights,
+// such as "sqrt(12^2 + 20^2 + 9^2) = 24.70". Weights other than 1 are shown
+// as factors of their term, as in "sqrt(2*12^2 + 20^2 + 0.5*9^2) = 30.82".
+func (m ABCMetrics) FormulaWith(w Weights) string {
+	terms := make([]string, 0, 3)
+	for _, term := range []struct {
+		weight float64
+		count  int
+	}{{w.A, m.Assignments}, {w.B, m.Branches}, {w.C, m.Conditions}} {
+		if term.weight == 1 {
+			terms = append(terms, fmt.Sprintf("%d^2", term.count))
+		} else {
+			terms = append(terms, fmt.Sprintf("%g*%d^2", term.weight, term.count))
+		}
+	}
+	return fmt.Sprintf("sqrt(%s) = %.2f", strings.Join(terms, " + "), m.ScoreWith(w))
 }
 
 // CombineMetrics combines multiple ABCMetrics into a single metric
@@ -41,25 +201,107 @@ func CombineMetrics(metrics ...ABCMetrics) ABCMetrics {
 		combined.Assignments += m.Assignments
 		combined.Branches += m.Branches
 		combined.Conditions += m.Conditions
+		combined.Guards += m.Guards
+		combined.MaxNesting = max(combined.MaxNesting, m.MaxNesting)
+		combined.Lines += m.Lines
+		combined.Partial = combined.Partial || m.Partial
 
 		// Combine detail lists
 		combined.AssignmentList = append(combined.AssignmentList, m.AssignmentList...)
 		combined.BranchList = append(combined.BranchList, m.BranchList...)
 		combined.ConditionList = append(combined.ConditionList, m.ConditionList...)
+		combined.Warnings = append(combined.Warnings, m.Warnings...)
 	}
 	return combined
 }
 
-// SeverityLevel returns a human-readable severity level based on ABC score
+// Weights scales the assignment, branch and condition terms of the score,
+// letting a policy emphasize, for example, conditions over assignments
+type Weights struct {
+	A float64 `json:"a" yaml:"a"` // Weight of the assignment term
+	B float64 `json:"b" yaml:"b"` // Weight of the branch term
+	C float64 `json:"c" yaml:"c"` // Weight of the condition term
+}
+
+// DefaultWeights weighs every term equally, giving the classic score
+var DefaultWeights = Weights{A: 1, B: 1, C: 1}
+
+// Validate checks that the weights are not negative and not all zero
+func (w Weights) Validate() error {
+	if w.A < 0 || w.B < 0 || w.C < 0 || w.A+w.B+w.C == 0 {
+		return fmt.Errorf("score weights must not be negative or all zero, got a=%g b=%g c=%g", w.A, w.B, w.C)
+	}
+	return nil
+}
+
+// SeverityConfig holds the score cutoffs between severity levels. A score
+// below Low is Low, below Medium is Medium, below High is High and anything
+// else is Very High.
+type SeverityConfig struct {
+	Low    float64 `json:"low" yaml:"low"`       // Upper bound of the Low level
+	Medium float64 `json:"medium" yaml:"medium"` // Upper bound of the Medium level
+	High   float64 `json:"high" yaml:"high"`     // Upper bound of the High level
+}
+
+// DefaultSeverityConfig holds the default severity cutoffs
+var DefaultSeverityConfig = SeverityConfig{Low: 10, Medium: 20, High: 40}
+
+// Validate checks that the cutoffs are positive and strictly increasing
+func (c SeverityConfig) Validate() error {
+	if c.Low <= 0 || c.Medium <= c.Low || c.High <= c.Medium {
+		return fmt.Errorf("severity cutoffs must be positive and increasing, got low=%g medium=%g high=%g", c.Low, c.Medium, c.High)
+	}
+	return nil
+}
+
+// SeverityLevel returns a human-readable severity level based on ABC score,
+// using the default cutoffs
 func SeverityLevel(score float64) string {
+	return SeverityLevelWith(score, DefaultSeverityConfig)
+}
+
+// SeverityLevelWith returns a human-readable severity level based on ABC
+// score, using the given cutoffs
+func SeverityLevelWith(score float64, cfg SeverityConfig) string {
 	switch {
-	case score < 10:
+	case score < cfg.Low:
 		return "Low"
-	case score < 20:
+	case score < cfg.Medium:
 		return "Medium"
-	case score < 40:
+	case score < cfg.High:
 		return "High"
 	default:
 		return "Very High"
 	}
 }
+
+// SeverityLevels lists the severity levels from lowest to highest
+var SeverityLevels = []string{"Low", "Medium", "High", "Very High"}
+
+// ParseSeverityLevel returns the severity level named by s, one of low,
+// medium, high or very-high, matched case-insensitively
+func ParseSeverityLevel(s string) (string, error) {
+	name := strings.ReplaceAll(strings.ToLower(s), "-", " ")
+	for _, level := range SeverityLevels {
+		if strings.ToLower(level) == name {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("unknown severity %q (supported: low, medium, high, very-high)", s)
+}
+
+// SeverityAtLeast reports whether level is the same as or above min
+func SeverityAtLeast(level, min string) bool {
+	return severityRank(level) >= severityRank(min)
+}
+
+// severityRank returns the position of the level in SeverityLevels, or -1
+// for an unknown level
+func severityRank(level string) int {
+	for i, l := range SeverityLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// BaselineVersion is the version of the baseline file format
+const BaselineVersion = 1
+
+// Baseline file formats
+const (
+	// BaselineFormatABC is the format written by WriteBaseline: an object
+	// with the format version and a files object of scores per path
+	BaselineFormatABC = "abc"
+	// BaselineFormatSimple is a flat object of scores per path, the shape
+	// many other complexity tools export
+	BaselineFormatSimple = "simple"
+)
+
+// BaselineFormats lists the formats ReadBaseline accepts
+var BaselineFormats = []string{BaselineFormatABC, BaselineFormatSimple}
+
+// Baseline records the score of every analyzed file at a point in time, so
+// later runs can report only the files whose complexity grew since then
+type Baseline struct {
+	Version int                `json:"version"` // Baseline file format version
+	Files   map[string]float64 `json:"files"`   // ABC score per file path
+}
+
+// NewBaseline builds a baseline from the given results, scored with the
+// given weights
+func NewBaseline(results []FileResult, weights metrics.Weights) Baseline {
+	files := make(map[string]float64, len(results))
+	for _, result := range results {
+		files[result.Path] = result.Metrics.ScoreWith(weights)
+	}
+	return Baseline{Version: BaselineVersion, Files: files}
+}
+
+// WriteBaseline saves the given baseline as a baseline file
+func WriteBaseline(path string, baseline Baseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing baseline: %w", err)
+	}
+	return nil
+}
+
+// ReadBaseline loads a baseline file in the given format: one previously
+// written by WriteBaseline for BaselineFormatABC, or a flat {path: score}
+// object for BaselineFormatSimple
+func ReadBaseline(path, format string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, fmt.Errorf("error reading baseline: %w", err)
+	}
+
+	switch format {
+	case BaselineFormatABC:
+	case BaselineFormatSimple:
+		var files map[string]float64
+		if err := json.Unmarshal(data, &files); err != nil {
+			return Baseline{}, fmt.Errorf("error decoding baseline: %w", err)
+		}
+		return Baseline{Version: BaselineVersion, Files: files}, nil
+	default:
+		return Baseline{}, fmt.Errorf("unsupported baseline format %q (expected abc or simple)", format)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return Baseline{}, fmt.Errorf("error decoding baseline: %w", err)
+	}
+
+	if baseline.Version != BaselineVersion {
+		return Baseline{}, fmt.Errorf("unsupported baseline version %d (expected %d)", baseline.Version, BaselineVersion)
+	}
+	return baseline, nil
+}
+
+// Regression describes a file whose score grew past its baseline
+type Regression struct {
+	Path     string  // Path of the analyzed file
+	Score    float64 // Current ABC score
+	Baseline float64 // Score recorded in the baseline, 0 for new files
+	New      bool    // Whether the file is missing from the baseline
+}
+
+// Regressions lists the results whose score exceeds their baseline score by
+// more than the tolerance. Files missing from the baseline have no baseline
+// to hide behind and are always reported. Scores use the given weights, which
+// should match the ones the baseline was written with.
+func (b Baseline) Regressions(results []FileResult, tolerance float64, weights metrics.Weights) []Regression {
+	var regressions []Regression
+	for _, result := range results {
+		score := result.Metrics.ScoreWith(weights)
+		previous, ok := b.Files[result.Path]
+		if !ok {
+			regressions = append(regressions, Regression{Path: result.Path, Score: score, New: true})
+			continue
+		}
+		if score > previous+tolerance {
+			regressions = append(regressions, Regression{Path: result.Path, Score: score, Baseline: previous})
+		}
+	}
+	return regressions
+}
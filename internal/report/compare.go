@@ -0,0 +1,172 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// Statuses of a file in a comparison
+const (
+	StatusRegressed = "regressed" // Score grew by more than the tolerance
+	StatusImproved  = "improved"  // Score went down
+	StatusUnchanged = "unchanged" // Score is the same or grew within the tolerance
+	StatusAdded     = "added"     // Only in the new report
+	StatusRemoved   = "removed"   // Only in the old report
+)
+
+// FileDelta compares one file between two reports. Old is nil for added
+// files and New is nil for removed ones.
+type FileDelta struct {
+	Path   string      `json:"path"`
+	Status string      `json:"status"`
+	Old    *JSONResult `json:"old,omitempty"`
+	New    *JSONResult `json:"new,omitempty"`
+}
+
+// ComparisonTotals holds the combined counts of all files of a report
+type ComparisonTotals struct {
+	Assignments int     `json:"assignments"`
+	Branches    int     `json:"branches"`
+	Conditions  int     `json:"conditions"`
+	Score       float64 `json:"score"` // Combined ABC score rounded to two decimals
+}
+
+// Comparison is the result of comparing two JSON reports
+type Comparison struct {
+	Files     []FileDelta      `json:"files"` // Every file of either report, in path order
+	Regressed int              `json:"regressed"`
+	Improved  int              `json:"improved"`
+	Unchanged int              `json:"unchanged"`
+	Added     int              `json:"added"`
+	Removed   int              `json:"removed"`
+	Old       ComparisonTotals `json:"old"` // Totals of the old report
+	New       ComparisonTotals `json:"new"` // Totals of the new report
+}
+
+// ReadJSONReport loads a report written with --format json: a single file
+// object, an array of them, or the object holding the files together with
+// the total or the failures.
+// Details and per-function metrics are dropped, only the file metrics are
+// kept.
+func ReadJSONReport(path string) ([]JSONResult, error) {
+	results, err := readJSONResults(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Details = nil
+		results[i].Functions = nil
+	}
+	return results, nil
+}
+
+// readJSONResults decodes the file results of a report in any of the forms
+// accepted by ReadJSONReport
+func readJSONResults(path string) ([]JSONResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading report: %w", err)
+	}
+
+	var results []JSONResult
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("error decoding report %s: %w", path, err)
+		}
+		if _, ok := fields["files"]; ok {
+			var wrapped JSONReport
+			if err := json.Unmarshal(trimmed, &wrapped); err != nil {
+				return nil, fmt.Errorf("error decoding report %s: %w", path, err)
+			}
+			results = wrapped.Files
+		} else {
+			var result JSONResult
+			if err := json.Unmarshal(trimmed, &result); err != nil {
+				return nil, fmt.Errorf("error decoding report %s: %w", path, err)
+			}
+			results = []JSONResult{result}
+		}
+	} else if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("error decoding report %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// Compare matches the files of two reports by path. A file regressed when its
+// score grew by more than the tolerance. Combined totals are scored with the
+// given weights, while per-file scores are taken from the reports as is.
+func Compare(before, after []JSONResult, tolerance float64, weights metrics.Weights) Comparison {
+	byPath := make(map[string]*FileDelta, len(before)+len(after))
+	for i := range before {
+		byPath[before[i].Path] = &FileDelta{Path: before[i].Path, Old: &before[i]}
+	}
+	for i := range after {
+		delta, ok := byPath[after[i].Path]
+		if !ok {
+			delta = &FileDelta{Path: after[i].Path}
+			byPath[after[i].Path] = delta
+		}
+		delta.New = &after[i]
+	}
+
+	c := Comparison{
+		Files: make([]FileDelta, 0, len(byPath)),
+		Old:   comparisonTotals(before, weights),
+		New:   comparisonTotals(after, weights),
+	}
+	for _, delta := range byPath {
+		switch {
+		case delta.Old == nil:
+			delta.Status = StatusAdded
+			c.Added++
+		case delta.New == nil:
+			delta.Status = StatusRemoved
+			c.Removed++
+		case delta.New.Score > delta.Old.Score+tolerance:
+			delta.Status = StatusRegressed
+			c.Regressed++
+		case delta.New.Score < delta.Old.Score:
+			delta.Status = StatusImproved
+			c.Improved++
+		default:
+			delta.Status = StatusUnchanged
+			c.Unchanged++
+		}
+		c.Files = append(c.Files, *delta)
+	}
+	sort.Slice(c.Files, func(i, j int) bool {
+		return c.Files[i].Path < c.Files[j].Path
+	})
+	return c
+}
+
+// comparisonTotals combines the counts of the results
+func comparisonTotals(results []JSONResult, weights metrics.Weights) ComparisonTotals {
+	var m metrics.ABCMetrics
+	for _, result := range results {
+		m.Assignments += result.Assignments
+		m.Branches += result.Branches
+		m.Conditions += result.Conditions
+	}
+	return ComparisonTotals{
+		Assignments: m.Assignments,
+		Branches:    m.Branches,
+		Conditions:  m.Conditions,
+		Score:       RoundScore(m.ScoreWith(weights)),
+	}
+}
+
+// WriteComparisonJSON writes the comparison as indented JSON
+func WriteComparisonJSON(w io.Writer, c Comparison) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(c)
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// CSVFields lists the columns the CSV output can hold, in their default order
+var CSVFields = []string{"path", "function", "assignments", "branches", "conditions", "score", "severity", "lines", "density"}
+
+// defaultCSVFields are the columns written when none are selected; function
+// is added after path with byFunction
+var defaultCSVFields = []string{"path", "assignments", "branches", "conditions", "score", "severity"}
+
+// ValidateCSVFields checks that every field is one of CSVFields
+func ValidateCSVFields(fields []string) error {
+	for _, field := range fields {
+		if !isCSVField(field) {
+			return fmt.Errorf("unknown field %q (expected %s)", field, strings.Join(CSVFields, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateCSVTotal checks that the total row can be told apart from the file
+// rows, which it is by the path column holding "TOTAL"
+func ValidateCSVTotal(fields []string, withTotal bool) error {
+	if withTotal && len(fields) > 0 && !isSelected(fields, "path") {
+		return fmt.Errorf("the total row needs the path column, which marks it as TOTAL; add path to the fields")
+	}
+	return nil
+}
+
+// isSelected reports whether the field is among the selected fields
+func isSelected(fields []string, field string) bool {
+	for _, selected := range fields {
+		if selected == field {
+			return true
+		}
+	}
+	return false
+}
+
+// isCSVField reports whether the field is one of CSVFields
+func isCSVField(field string) bool {
+	return isSelected(CSVFields, field)
+}
+
+// WriteCSV writes the results as CSV with a header row, one row per file. With
+// byFunction there is one row per function instead and an extra function
+// column; files without a function breakdown keep a single row with an empty
+// function name. With a total, a last row with "TOTAL" as its path holds the
+// combined metrics, which requires the path column. fields selects and orders
+// the columns, by default path, assignments, branches, conditions, score and
+// severity, with function after path with byFunction. Severities are rated
+// with the given cutoffs.
+func WriteCSV(w io.Writer, results []FileResult, byFunction bool, total *Total, fields []string, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	if err := ValidateCSVFields(fields); err != nil {
+		return err
+	}
+	if err := ValidateCSVTotal(fields, total != nil); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		fields = defaultCSVFields
+		if byFunction {
+			fields = append([]string{"path", "function"}, defaultCSVFields[1:]...)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(fields); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if !byFunction || len(result.Functions) == 0 {
+			if err := writer.Write(csvRow(fields, result.Path, "", result.Metrics, weights, severity)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, fn := range result.Functions {
+			if err := writer.Write(csvRow(fields, result.Path, fn.Name, fn.Metrics, weights, severity)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if total != nil {
+		if err := writer.Write(csvRow(fields, totalPath, "", total.Metrics, weights, severity)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// totalPath is the path column of the total row
+const totalPath = "TOTAL"
+
+// csvRow returns the selected columns of a CSV row
+func csvRow(fields []string, path, function string, m metrics.ABCMetrics, weights metrics.Weights, severity metrics.SeverityConfig) []string {
+	row := make([]string, len(fields))
+	for i, field := range fields {
+		switch field {
+		case "path":
+			row[i] = path
+		case "function":
+			row[i] = function
+		case "assignments":
+			row[i] = strconv.Itoa(m.Assignments)
+		case "branches":
+			row[i] = strconv.Itoa(m.Branches)
+		case "conditions":
+			row[i] = strconv.Itoa(m.Conditions)
+		case "score":
+			row[i] = strconv.FormatFloat(RoundScore(m.ScoreWith(weights)), 'f', 2, 64)
+		case "severity":
+			row[i] = metrics.SeverityLevelWith(m.ScoreWith(weights), severity)
+		case "lines":
+			row[i] = strconv.Itoa(m.Lines)
+		case "density":
+			row[i] = strconv.FormatFloat(RoundScore(m.ScoreDensityWith(weights)), 'f', 2, 64)
+		}
+	}
+	return row
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+func TestWriteCSVTotalRow(t *testing.T) {
+	results := []FileResult{
+		{Path: "a.go", Metrics: metrics.ABCMetrics{Assignments: 3, Branches: 4}},
+		{Path: "b.go", Metrics: metrics.ABCMetrics{Conditions: 2}},
+	}
+	total := NewTotal(results)
+
+	tests := []struct {
+		name    string
+		fields  []string
+		want    string
+		wantErr bool
+	}{
+		{"default fields", nil, "TOTAL,3,4,2,5.39,Low", false},
+		{"selected fields with path", []string{"score", "path"}, "5.39,TOTAL", false},
+		{"selected fields without path", []string{"score"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteCSV(&buf, results, false, &total, tt.fields, metrics.DefaultWeights, metrics.DefaultSeverityConfig)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("WriteCSV succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WriteCSV: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if got := lines[len(lines)-1]; got != tt.want {
+				t.Errorf("total row: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// DirectoryResult holds the analysis results of the files of a directory
+type DirectoryResult struct {
+	Path  string       // Directory of the files, with forward slashes
+	Files []FileResult // Per-file results, in path order
+}
+
+// Metrics combines the metrics of every file in the directory
+func (d DirectoryResult) Metrics() metrics.ABCMetrics {
+	all := make([]metrics.ABCMetrics, 0, len(d.Files))
+	for _, file := range d.Files {
+		all = append(all, file.Metrics)
+	}
+	return metrics.CombineMetrics(all...)
+}
+
+// GroupByDirectory groups the results by the directory of their path, in
+// directory order. With a depth above 0, directories are cut to their first
+// depth elements, so deeper directories roll up into their ancestor; files
+// above that depth stay in their own directory.
+func GroupByDirectory(results []FileResult, depth int) []DirectoryResult {
+	byDir := make(map[string]*DirectoryResult)
+	var dirs []string
+	for _, result := range results {
+		dir := directoryAtDepth(path.Dir(result.Path), depth)
+		group, ok := byDir[dir]
+		if !ok {
+			group = &DirectoryResult{Path: dir}
+			byDir[dir] = group
+			dirs = append(dirs, dir)
+		}
+		group.Files = append(group.Files, result)
+	}
+
+	sort.Strings(dirs)
+	grouped := make([]DirectoryResult, 0, len(dirs))
+	for _, dir := range dirs {
+		group := byDir[dir]
+		sort.SliceStable(group.Files, func(i, j int) bool {
+			return group.Files[i].Path < group.Files[j].Path
+		})
+		grouped = append(grouped, *group)
+	}
+	return grouped
+}
+
+// directoryAtDepth cuts a slash-separated directory to its first depth
+// elements, keeping the leading slash of absolute directories. A depth of 0
+// keeps the whole directory.
+func directoryAtDepth(dir string, depth int) string {
+	if depth <= 0 || dir == "." {
+		return dir
+	}
+
+	prefix := ""
+	if strings.HasPrefix(dir, "/") {
+		prefix = "/"
+		dir = strings.TrimPrefix(dir, "/")
+	}
+	elements := strings.Split(dir, "/")
+	if len(elements) > depth {
+		elements = elements[:depth]
+	}
+	return prefix + strings.Join(elements, "/")
+}
+
+// JSONDirectory is the JSON representation of a directory's combined metrics
+type JSONDirectory struct {
+	Directory   string  `json:"directory"`   // Directory of the files
+	Files       int     `json:"files"`       // Number of files combined
+	Assignments int     `json:"assignments"` // Number of assignments in all files
+	Branches    int     `json:"branches"`    // Number of branches in all files
+	Conditions  int     `json:"conditions"`  // Number of conditions in all files
+	Score       float64 `json:"score"`       // Combined ABC score rounded to two decimals
+	Severity    string  `json:"severity"`    // Severity level of the combined score
+	Lines       int     `json:"lines"`       // Number of source lines in all files
+	Density     float64 `json:"density"`     // Combined ABC score per 100 lines, rounded to two decimals
+}
+
+// WriteDirectoriesJSON writes the directory results as an indented JSON
+// array, one object per directory with its combined metrics
+func WriteDirectoriesJSON(w io.Writer, dirs []DirectoryResult, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	jsonDirs := make([]JSONDirectory, 0, len(dirs))
+	for _, dir := range dirs {
+		m := dir.Metrics()
+		jsonDirs = append(jsonDirs, JSONDirectory{
+			Directory:   dir.Path,
+			Files:       len(dir.Files),
+			Assignments: m.Assignments,
+			Branches:    m.Branches,
+			Conditions:  m.Conditions,
+			Score:       RoundScore(m.ScoreWith(weights)),
+			Severity:    metrics.SeverityLevelWith(m.ScoreWith(weights), severity),
+			Lines:       m.Lines,
+			Density:     RoundScore(m.ScoreDensityWith(weights)),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(jsonDirs)
+}
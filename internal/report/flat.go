@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// JSONFlatDetail is a single counted item in the flat detail form, carrying
+// the file it was found in so records of all files fit in one table
+type JSONFlatDetail struct {
+	File     string `json:"file"`            // Path of the file
+	Line     int    `json:"line"`            // Line number
+	Col      int    `json:"col"`             // Column number
+	Category string `json:"category"`        // assignment, branch or condition
+	Text     string `json:"text"`            // Short description or snippet
+	Context  string `json:"context"`         // Additional context
+	Count    int    `json:"count,omitempty"` // Number of items counted, only set when more than one
+}
+
+// FlattenDetails returns the detail lists of every result as one list of
+// records, in path order and by position within each file
+func FlattenDetails(results []FileResult) []JSONFlatDetail {
+	records := []JSONFlatDetail{}
+	for _, result := range results {
+		start := len(records)
+		for _, list := range []struct {
+			category string
+			details  []metrics.MetricDetail
+		}{
+			{"assignment", result.Metrics.AssignmentList},
+			{"branch", result.Metrics.BranchList},
+			{"condition", result.Metrics.ConditionList},
+		} {
+			for _, d := range list.details {
+				records = append(records, JSONFlatDetail{
+					File:     result.Path,
+					Line:     d.Line,
+					Col:      d.Col,
+					Category: list.category,
+					Text:     d.Text,
+					Context:  d.Context,
+					Count:    d.Count,
+				})
+			}
+		}
+
+		file := records[start:]
+		sort.SliceStable(file, func(i, j int) bool {
+			if file[i].Line != file[j].Line {
+				return file[i].Line < file[j].Line
+			}
+			return file[i].Col < file[j].Col
+		})
+	}
+	return records
+}
+
+// WriteFlatDetailsJSON writes the detail lists of the results as one indented
+// JSON array of records, ready to be loaded into columnar stores without
+// reshaping
+func WriteFlatDetailsJSON(w io.Writer, results []FileResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(FlattenDetails(results))
+}
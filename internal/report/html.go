@@ -0,0 +1,199 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// htmlFile is a single table row of the HTML report
+type htmlFile struct {
+	Path        string
+	Assignments int
+	Branches    int
+	Conditions  int
+	Score       float64
+	Severity    string
+	Class       string // CSS class of the severity
+	Details     []htmlDetailList
+}
+
+// htmlDetailList is one of the expandable detail lists of a file
+type htmlDetailList struct {
+	Title   string
+	Details []metrics.MetricDetail
+}
+
+// HTML report themes
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+// HTMLThemes lists the themes of the HTML report
+var HTMLThemes = []string{ThemeLight, ThemeDark}
+
+// ValidateTheme checks that the theme is one of HTMLThemes
+func ValidateTheme(theme string) error {
+	switch theme {
+	case ThemeLight, ThemeDark:
+		return nil
+	default:
+		return fmt.Errorf("unsupported theme %q (expected light or dark)", theme)
+	}
+}
+
+// htmlReport is the data rendered by htmlTemplate
+type htmlReport struct {
+	Theme     string
+	FileCount int
+	Total     htmlFile
+	Files     []htmlFile
+}
+
+// WriteHTML writes a self-contained HTML page with a sortable table of the
+// results, color-coded by severity, with expandable detail lists and a
+// summary of the combined metrics, styled with the given theme
+func WriteHTML(w io.Writer, results []FileResult, theme string, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	all := make([]metrics.ABCMetrics, 0, len(results))
+	data := htmlReport{Theme: theme, FileCount: len(results)}
+	for _, result := range results {
+		all = append(all, result.Metrics)
+		data.Files = append(data.Files, newHTMLFile(result.Path, result.Metrics, weights, severity))
+	}
+	data.Total = newHTMLFile("Total", metrics.CombineMetrics(all...), weights, severity)
+
+	return htmlTemplate.Execute(w, data)
+}
+
+// newHTMLFile converts metrics into a table row
+func newHTMLFile(path string, m metrics.ABCMetrics, weights metrics.Weights, severity metrics.SeverityConfig) htmlFile {
+	level := metrics.SeverityLevelWith(m.ScoreWith(weights), severity)
+	return htmlFile{
+		Path:        path,
+		Assignments: m.Assignments,
+		Branches:    m.Branches,
+		Conditions:  m.Conditions,
+		Score:       RoundScore(m.ScoreWith(weights)),
+		Severity:    level,
+		Class:       strings.ToLower(strings.ReplaceAll(level, " ", "-")),
+		Details: []htmlDetailList{
+			{Title: "Assignments", Details: metrics.SortDetails(m.AssignmentList)},
+			{Title: "Branches", Details: metrics.SortDetails(m.BranchList)},
+			{Title: "Conditions", Details: metrics.SortDetails(m.ConditionList)},
+		},
+	}
+}
+
+// htmlTemplate renders the HTML report. Styles and the sorting script are
+// inlined so the page has no external assets. Colors are CSS variables set
+// per theme by the data-theme attribute; the severity badges of both themes
+// keep a contrast ratio of at least 4.5:1 with their text.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en" data-theme="{{.Theme}}">
+<head>
+<meta charset="utf-8">
+<title>ABC Metrics Report</title>
+<style>
+:root {
+  --text: #222; --background: #fff; --border: #ddd;
+  --header: #f4f4f4; --header-hover: #e8e8e8;
+  --low: #d4edda; --medium: #fff3cd; --high: #ffd8a8; --very-high: #f8d7da;
+}
+[data-theme="dark"] {
+  --text: #e6e6e6; --background: #1b1b1f; --border: #3a3a40;
+  --header: #26262b; --header-hover: #323238;
+  --low: #1e4d2b; --medium: #5c4a0c; --high: #6b3a0f; --very-high: #6e1f26;
+}
+body { font-family: system-ui, sans-serif; margin: 2em; color: var(--text); background: var(--background); }
+h1 { font-size: 1.5em; }
+.summary { margin-bottom: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 0.4em 0.8em; border-bottom: 1px solid var(--border); text-align: left; vertical-align: top; }
+th { cursor: pointer; background: var(--header); user-select: none; }
+th:hover { background: var(--header-hover); }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.severity { padding: 0.1em 0.5em; border-radius: 0.3em; white-space: nowrap; color: var(--text); }
+.low { background: var(--low); }
+.medium { background: var(--medium); }
+.high { background: var(--high); }
+.very-high { background: var(--very-high); }
+details { margin: 0.2em 0; }
+summary { cursor: pointer; }
+ol { margin: 0.3em 0; font-family: monospace; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>ABC Metrics Report</h1>
+<div class="summary">
+<p>Files analyzed: {{.FileCount}}</p>
+<p>Total: {{.Total.Score}} (A={{.Total.Assignments}}, B={{.Total.Branches}}, C={{.Total.Conditions}})
+<span class="severity {{.Total.Class}}">{{.Total.Severity}}</span></p>
+</div>
+<table id="results">
+<thead>
+<tr>
+<th data-type="text">Path</th>
+<th data-type="num">A</th>
+<th data-type="num">B</th>
+<th data-type="num">C</th>
+<th data-type="num">Score</th>
+<th data-type="num">Severity</th>
+<th data-type="none">Details</th>
+</tr>
+</thead>
+<tbody>
+{{- range .Files}}
+<tr>
+<td>{{.Path}}</td>
+<td class="num">{{.Assignments}}</td>
+<td class="num">{{.Branches}}</td>
+<td class="num">{{.Conditions}}</td>
+<td class="num">{{.Score}}</td>
+<td data-sort="{{.Score}}"><span class="severity {{.Class}}">{{.Severity}}</span></td>
+<td>
+{{- range .Details}}
+<details><summary>{{.Title}} ({{len .Details}})</summary>
+<ol>
+{{- range .Details}}
+<li>Line {{.Line}}: {{.Text}} ({{.Context}})</li>
+{{- end}}
+</ol>
+</details>
+{{- end}}
+</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+document.querySelectorAll("#results th").forEach(function (th, column) {
+  var ascending = false;
+  th.addEventListener("click", function () {
+    var type = th.dataset.type;
+    if (type === "none") {
+      return;
+    }
+    ascending = !ascending;
+    var tbody = document.querySelector("#results tbody");
+    var rows = Array.from(tbody.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[column].dataset.sort || a.cells[column].textContent;
+      var y = b.cells[column].dataset.sort || b.cells[column].textContent;
+      var order = type === "num" ? parseFloat(x) - parseFloat(y) : x.localeCompare(y);
+      return ascending ? order : -order;
+    });
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,229 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// JSONOptions selects the optional parts of the JSON representations
+type JSONOptions struct {
+	Details    bool      // Include the detail lists of each file
+	Formula    bool      // Include the derivation of each score
+	SourceHash bool      // Include the hash of the content each file was analyzed from
+	Meta       *JSONMeta // Describes the run at the top of the report when set
+}
+
+// JSONMeta describes the run a JSON report was produced by, so stored
+// reports can be compared and audited later
+type JSONMeta struct {
+	Tool            string            `json:"tool"`            // Name of the tool, always "abc"
+	Version         string            `json:"version"`         // Version of the tool, "(devel)" for source builds
+	AnalyzerVersion int               `json:"analyzerVersion"` // Version of the counting rules, see analyzer.Version
+	Timestamp       string            `json:"timestamp"`       // Start of the run in RFC 3339 format, UTC
+	Paths           []string          `json:"paths"`           // Paths the run was given
+	Options         map[string]string `json:"options"`         // Flags set on the command line or in the config file
+}
+
+// JSONResult is the stable JSON representation of a single file's metrics
+type JSONResult struct {
+	Path            string       `json:"path"`                     // Path of the analyzed file
+	SourceHash      string       `json:"sourceHash,omitempty"`     // SHA-256 of the analyzed content, only present with --include-source-hash
+	Assignments     int          `json:"assignments"`              // Number of assignments
+	Branches        int          `json:"branches"`                 // Number of branches
+	Conditions      int          `json:"conditions"`               // Number of conditions
+	UniqueBranches  *int         `json:"uniqueBranches,omitempty"` // Number of distinct callees among the branches, absent when unknown
+	Score           float64      `json:"score"`                    // ABC score rounded to two decimals
+	Formula         string       `json:"formula,omitempty"`        // Derivation of the score, such as "sqrt(12^2 + 20^2 + 9^2) = 24.70"
+	Severity        string       `json:"severity"`                 // Severity level of the score
+	Lines           int          `json:"lines"`                    // Number of source lines analyzed
+	Density         float64      `json:"density"`                  // ABC score per 100 lines, rounded to two decimals
+	DecisionDensity float64      `json:"decisionDensity"`          // Conditions per 100 lines, rounded to two decimals
+	MaxNesting      int          `json:"maxNesting,omitempty"`     // Deepest nesting depth of a condition, only present with --nesting
+	Partial         bool         `json:"partial,omitempty"`        // Set when syntax errors limited the analysis to part of the file
+	Details         *JSONDetails `json:"details,omitempty"`        // Detail lists sorted by position, only present with --show
+
+	Functions []JSONFunction `json:"functions,omitempty"` // Per-function metrics, only present with --by-function
+}
+
+// JSONFunction is the JSON representation of a single function's metrics
+type JSONFunction struct {
+	Name            string  `json:"name"`
+	StartLine       int     `json:"startLine"`
+	EndLine         int     `json:"endLine"`
+	Assignments     int     `json:"assignments"`
+	Branches        int     `json:"branches"`
+	Conditions      int     `json:"conditions"`
+	UniqueBranches  *int    `json:"uniqueBranches,omitempty"`
+	Score           float64 `json:"score"`
+	Formula         string  `json:"formula,omitempty"`
+	Severity        string  `json:"severity"`
+	Lines           int     `json:"lines"`
+	Density         float64 `json:"density"`
+	DecisionDensity float64 `json:"decisionDensity"`
+	Partial         bool    `json:"partial,omitempty"`
+}
+
+// JSONTotal is the JSON representation of the combined metrics of all files
+type JSONTotal struct {
+	Files       int     `json:"files"`             // Number of files combined
+	Assignments int     `json:"assignments"`       // Number of assignments in all files
+	Branches    int     `json:"branches"`          // Number of branches in all files
+	Conditions  int     `json:"conditions"`        // Number of conditions in all files
+	Score       float64 `json:"score"`             // Combined ABC score rounded to two decimals
+	Formula     string  `json:"formula,omitempty"` // Derivation of the combined score
+	Severity    string  `json:"severity"`          // Severity level of the combined score
+	Lines       int     `json:"lines"`             // Number of source lines in all files
+	Density     float64 `json:"density"`           // Combined ABC score per 100 lines, rounded to two decimals
+}
+
+// JSONReport is the JSON representation of the results together with their
+// combined total or the files that failed
+type JSONReport struct {
+	Meta     *JSONMeta    `json:"meta,omitempty"` // Present for several files by default and with --with-meta
+	Files    []JSONResult `json:"files"`
+	Total    *JSONTotal   `json:"total,omitempty"`    // Only present with --include-total
+	Failures []Failure    `json:"failures,omitempty"` // Files that could not be analyzed
+}
+
+// JSONDetails holds the detail lists of a JSONResult
+type JSONDetails struct {
+	Assignments []metrics.MetricDetail `json:"assignments"`
+	Branches    []metrics.MetricDetail `json:"branches"`
+	Conditions  []metrics.MetricDetail `json:"conditions"`
+}
+
+// NewJSONResult converts a file result into its JSON representation, rating
+// scores with the given severity cutoffs
+func NewJSONResult(result FileResult, opts JSONOptions, weights metrics.Weights, severity metrics.SeverityConfig) JSONResult {
+	m := result.Metrics
+	jsonResult := JSONResult{
+		Path:            result.Path,
+		Assignments:     m.Assignments,
+		Branches:        m.Branches,
+		Conditions:      m.Conditions,
+		UniqueBranches:  uniqueBranches(m, result.UniqueBranches),
+		Score:           RoundScore(m.ScoreWith(weights)),
+		Severity:        metrics.SeverityLevelWith(m.ScoreWith(weights), severity),
+		Lines:           m.Lines,
+		Density:         RoundScore(m.ScoreDensityWith(weights)),
+		DecisionDensity: RoundScore(m.DecisionDensity()),
+		MaxNesting:      m.MaxNesting,
+		Partial:         m.Partial,
+	}
+
+	if opts.Formula {
+		jsonResult.Formula = m.FormulaWith(weights)
+	}
+	if opts.SourceHash {
+		jsonResult.SourceHash = result.SourceHash
+	}
+	if opts.Details {
+		jsonResult.Details = &JSONDetails{
+			Assignments: nonNil(metrics.SortDetails(m.AssignmentList)),
+			Branches:    nonNil(metrics.SortDetails(m.BranchList)),
+			Conditions:  nonNil(metrics.SortDetails(m.ConditionList)),
+		}
+	}
+
+	for _, fn := range result.Functions {
+		var saved *int
+		if unique, ok := result.FunctionUniqueBranches[fn.StartLine]; ok {
+			saved = &unique
+		}
+		jsonFunction := JSONFunction{
+			Name:            fn.Name,
+			StartLine:       fn.StartLine,
+			EndLine:         fn.EndLine,
+			Assignments:     fn.Metrics.Assignments,
+			Branches:        fn.Metrics.Branches,
+			Conditions:      fn.Metrics.Conditions,
+			UniqueBranches:  uniqueBranches(fn.Metrics, saved),
+			Score:           RoundScore(fn.Metrics.ScoreWith(weights)),
+			Severity:        metrics.SeverityLevelWith(fn.Metrics.ScoreWith(weights), severity),
+			Lines:           fn.Metrics.Lines,
+			Density:         RoundScore(fn.Metrics.ScoreDensityWith(weights)),
+			DecisionDensity: RoundScore(fn.Metrics.DecisionDensity()),
+			Partial:         fn.Metrics.Partial,
+		}
+		if opts.Formula {
+			jsonFunction.Formula = fn.Metrics.FormulaWith(weights)
+		}
+		jsonResult.Functions = append(jsonResult.Functions, jsonFunction)
+	}
+
+	return jsonResult
+}
+
+// uniqueBranches returns the number of distinct callees of the metrics. When
+// the metrics have no branch list, the count saved with a result read back
+// from a report is used, which is nil when it is unknown.
+func uniqueBranches(m metrics.ABCMetrics, saved *int) *int {
+	if !m.UniqueBranchesKnown() {
+		return saved
+	}
+	unique := m.UniqueBranches()
+	return &unique
+}
+
+// NewJSONTotal converts a total into its JSON representation
+func NewJSONTotal(total Total, opts JSONOptions, weights metrics.Weights, severity metrics.SeverityConfig) JSONTotal {
+	m := total.Metrics
+	jsonTotal := JSONTotal{
+		Files:       total.Files,
+		Assignments: m.Assignments,
+		Branches:    m.Branches,
+		Conditions:  m.Conditions,
+		Score:       RoundScore(m.ScoreWith(weights)),
+		Severity:    metrics.SeverityLevelWith(m.ScoreWith(weights), severity),
+		Lines:       m.Lines,
+		Density:     RoundScore(m.ScoreDensityWith(weights)),
+	}
+	if opts.Formula {
+		jsonTotal.Formula = m.FormulaWith(weights)
+	}
+	return jsonTotal
+}
+
+// WriteJSON writes the results as indented JSON in one of three shapes. With
+// a total, failures or meta data, it is a JSONReport object holding them and
+// the files. Otherwise a single result is a bare object unless asArray is
+// set, and any other number of results is an array.
+func WriteJSON(w io.Writer, results []FileResult, asArray bool, opts JSONOptions, total *Total, failures []Failure, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	jsonResults := make([]JSONResult, 0, len(results))
+	for _, result := range results {
+		jsonResults = append(jsonResults, NewJSONResult(result, opts, weights, severity))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	if total != nil || len(failures) > 0 || opts.Meta != nil {
+		jsonReport := JSONReport{Meta: opts.Meta, Files: jsonResults, Failures: failures}
+		if total != nil {
+			jsonTotal := NewJSONTotal(*total, opts, weights, severity)
+			jsonReport.Total = &jsonTotal
+		}
+		return encoder.Encode(jsonReport)
+	}
+	if !asArray && len(jsonResults) == 1 {
+		return encoder.Encode(jsonResults[0])
+	}
+	return encoder.Encode(jsonResults)
+}
+
+// RoundScore rounds a score to two decimals, the precision used in all output
+func RoundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+// nonNil returns an empty slice for nil so it encodes as [] instead of null
+func nonNil(details []metrics.MetricDetail) []metrics.MetricDetail {
+	if details == nil {
+		return []metrics.MetricDetail{}
+	}
+	return details
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+func TestWriteJSONShapes(t *testing.T) {
+	a := FileResult{Path: "a.go", Metrics: metrics.ABCMetrics{Assignments: 3}}
+	b := FileResult{Path: "b.go", Metrics: metrics.ABCMetrics{Branches: 2}}
+	one, two := []FileResult{a}, []FileResult{a, b}
+	total := NewTotal(two)
+	failures := []Failure{{Path: "c.go", Error: "syntax error"}}
+	meta := &JSONMeta{Tool: "abc"}
+
+	tests := []struct {
+		name     string
+		results  []FileResult
+		asArray  bool
+		meta     *JSONMeta
+		total    *Total
+		failures []Failure
+		want     string   // Top-level shape: object, array or report
+		keys     []string // Keys of a report object
+	}{
+		{"single file", one, false, nil, nil, nil, "object", nil},
+		{"single file as array", one, true, nil, nil, nil, "array", nil},
+		{"several files", two, false, nil, nil, nil, "array", nil},
+		{"no files", nil, false, nil, nil, nil, "array", nil},
+		{"total", two, true, nil, &total, nil, "report", []string{"files", "total"}},
+		{"failures", one, false, nil, nil, failures, "report", []string{"failures", "files"}},
+		{"meta", one, false, meta, nil, nil, "report", []string{"files", "meta"}},
+		{"everything", two, true, meta, &total, failures, "report", []string{"failures", "files", "meta", "total"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := JSONOptions{Meta: tt.meta}
+			if err := WriteJSON(&buf, tt.results, tt.asArray, opts, tt.total, tt.failures, metrics.DefaultWeights, metrics.DefaultSeverityConfig); err != nil {
+				t.Fatalf("WriteJSON: %v", err)
+			}
+
+			var array []JSONResult
+			if json.Unmarshal(buf.Bytes(), &array) == nil {
+				if tt.want != "array" || len(array) != len(tt.results) {
+					t.Errorf("got an array of %d files, want %s", len(array), tt.want)
+				}
+				return
+			}
+
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(buf.Bytes(), &object); err != nil {
+				t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+			}
+			if _, ok := object["path"]; ok {
+				if tt.want != "object" {
+					t.Errorf("got a bare file object, want %s", tt.want)
+				}
+				return
+			}
+
+			keys := make([]string, 0, len(object))
+			for key := range object {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if tt.want != "report" || !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("got a report object with %v, want %s %v", keys, tt.want, tt.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// JSONLTotal is the last line of JSON Lines output with a total, telling it
+// apart from the file lines by its single "total" key
+type JSONLTotal struct {
+	Total JSONTotal `json:"total"`
+}
+
+// WriteJSONLine writes a single result as one line of compact JSON, in the
+// same representation as WriteJSON
+func WriteJSONLine(w io.Writer, result FileResult, opts JSONOptions, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	return encodeLine(w, NewJSONResult(result, opts, weights, severity))
+}
+
+// WriteJSONLTotal writes the total as the closing line of JSON Lines output
+func WriteJSONLTotal(w io.Writer, total Total, opts JSONOptions, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	return encodeLine(w, JSONLTotal{Total: NewJSONTotal(total, opts, weights, severity)})
+}
+
+// WriteJSONLFailure writes a file that could not be analyzed as one line,
+// told apart from the result lines by its "error" key
+func WriteJSONLFailure(w io.Writer, failure Failure) error {
+	return encodeLine(w, failure)
+}
+
+// WriteJSONL writes the results as JSON Lines, one file per line, followed by
+// the total when it is not nil
+func WriteJSONL(w io.Writer, results []FileResult, opts JSONOptions, total *Total, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	for _, result := range results {
+		if err := WriteJSONLine(w, result, opts, weights, severity); err != nil {
+			return err
+		}
+	}
+	if total != nil {
+		return WriteJSONLTotal(w, *total, opts, weights, severity)
+	}
+	return nil
+}
+
+// encodeLine writes v as compact JSON followed by a newline
+func encodeLine(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// The types below model the JUnit XML subset written by WriteJUnit
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// WriteJUnit writes a JUnit XML report with one test case per file. A file
+// fails when its score exceeds the threshold; a threshold of 0 uses the start
+// of the High severity level.
+func WriteJUnit(w io.Writer, results []FileResult, threshold float64, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	if threshold == 0 {
+		threshold = severity.Medium
+	}
+
+	suite := junitTestSuite{Name: "abc", TestCases: []junitTestCase{}}
+	for _, result := range results {
+		m := result.Metrics
+		testCase := junitTestCase{Name: result.Path, ClassName: "abc"}
+
+		if score := m.ScoreWith(weights); score > threshold {
+			level := metrics.SeverityLevelWith(score, severity)
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("ABC score %.2f (%s) exceeds threshold %.2f", score, level, threshold),
+				Type:    SARIFRuleID,
+				Text:    fmt.Sprintf("%s: %s, complexity %s", result.Path, m.String(), level),
+			}
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	doc := junitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// WriteMarkdown writes the results as a GitHub-flavored Markdown table with
+// one row per file and a totals row, ready to be posted as a pull request
+// comment. With withDetails, a collapsible section listing the counted items
+// follows for every file. Severities are rated with the given cutoffs.
+func WriteMarkdown(w io.Writer, results []FileResult, withDetails bool, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "| File | A | B | C | Score | Severity |")
+	fmt.Fprintln(bw, "| --- | ---: | ---: | ---: | ---: | --- |")
+
+	all := make([]metrics.ABCMetrics, 0, len(results))
+	for _, result := range results {
+		writeMarkdownRow(bw, markdownEscape(result.Path), result.Metrics, weights, severity)
+		all = append(all, result.Metrics)
+	}
+	writeMarkdownRow(bw, "**Total**", metrics.CombineMetrics(all...), weights, severity)
+
+	if withDetails {
+		for _, result := range results {
+			fmt.Fprintf(bw, "\n<details>\n<summary>%s</summary>\n\n", html.EscapeString(result.Path))
+			writeMarkdownDetails(bw, "Assignments", result.Metrics.AssignmentList)
+			writeMarkdownDetails(bw, "Branches", result.Metrics.BranchList)
+			writeMarkdownDetails(bw, "Conditions", result.Metrics.ConditionList)
+			fmt.Fprintln(bw, "</details>")
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeMarkdownRow writes one table row with the metrics of a file or total
+func writeMarkdownRow(w io.Writer, name string, m metrics.ABCMetrics, weights metrics.Weights, severity metrics.SeverityConfig) {
+	score := m.ScoreWith(weights)
+	fmt.Fprintf(w, "| %s | %d | %d | %d | %.2f | %s |\n",
+		name, m.Assignments, m.Branches, m.Conditions, RoundScore(score), metrics.SeverityLevelWith(score, severity))
+}
+
+// writeMarkdownDetails writes a detail list sorted by position, or nothing
+// when it is empty
+func writeMarkdownDetails(w io.Writer, title string, details []metrics.MetricDetail) {
+	if len(details) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "**%s**\n\n", title)
+	for _, d := range metrics.SortDetails(details) {
+		fmt.Fprintf(w, "- Line %d: `%s` (%s)\n", d.Line, strings.ReplaceAll(d.Text, "`", "'"), markdownEscape(d.Context))
+	}
+	fmt.Fprintln(w)
+}
+
+// markdownEscaper escapes the characters that would break a table cell or be
+// taken for Markdown or HTML markup
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", ">", "&gt;",
+)
+
+// markdownEscape escapes text for use in Markdown
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
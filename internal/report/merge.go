@@ -0,0 +1,114 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// Rules for files found in more than one merged report
+const (
+	MergeLast    = "last"    // The entry of the last report given wins
+	MergeError   = "error"   // Merging fails
+	MergeCombine = "combine" // The entries are combined as by CombineMetrics
+)
+
+// ReadJSONFiles loads a report in any of the forms accepted by
+// ReadJSONReport and converts it back into file results, keeping details
+// and per-function metrics. Scores are not read back but recalculated from
+// the counts when the results are written.
+func ReadJSONFiles(path string) ([]FileResult, error) {
+	jsonResults, err := readJSONResults(path)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]FileResult, 0, len(jsonResults))
+	for _, r := range jsonResults {
+		result := FileResult{
+			Path:           r.Path,
+			SourceHash:     r.SourceHash,
+			UniqueBranches: r.UniqueBranches,
+			Metrics: metrics.ABCMetrics{
+				Assignments: r.Assignments,
+				Branches:    r.Branches,
+				Conditions:  r.Conditions,
+				MaxNesting:  r.MaxNesting,
+				Lines:       r.Lines,
+				Partial:     r.Partial,
+			},
+		}
+		if r.Details != nil {
+			result.Metrics.AssignmentList = r.Details.Assignments
+			result.Metrics.BranchList = r.Details.Branches
+			result.Metrics.ConditionList = r.Details.Conditions
+		}
+		for _, fn := range r.Functions {
+			result.Functions = append(result.Functions, metrics.FunctionMetrics{
+				Name:      fn.Name,
+				StartLine: fn.StartLine,
+				EndLine:   fn.EndLine,
+				Metrics: metrics.ABCMetrics{
+					Assignments: fn.Assignments,
+					Branches:    fn.Branches,
+					Conditions:  fn.Conditions,
+					Lines:       fn.Lines,
+					Partial:     fn.Partial,
+				},
+			})
+			if fn.UniqueBranches != nil {
+				if result.FunctionUniqueBranches == nil {
+					result.FunctionUniqueBranches = make(map[int]int)
+				}
+				result.FunctionUniqueBranches[fn.StartLine] = *fn.UniqueBranches
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Merge combines the results of several reports into one, in path order.
+// Files found in only one report are taken as they are; files found in
+// several are resolved by the rule, one of the Merge constants.
+func Merge(reports [][]FileResult, rule string) ([]FileResult, error) {
+	byPath := make(map[string]*FileResult)
+	for _, results := range reports {
+		for _, result := range results {
+			existing, ok := byPath[result.Path]
+			if !ok {
+				result := result
+				byPath[result.Path] = &result
+				continue
+			}
+
+			switch rule {
+			case MergeLast:
+				*existing = result
+			case MergeError:
+				return nil, fmt.Errorf("%s is in more than one report", result.Path)
+			case MergeCombine:
+				existing.Metrics = metrics.CombineMetrics(existing.Metrics, result.Metrics)
+				existing.Functions = append(existing.Functions, result.Functions...)
+				// Distinct callees of different reports cannot be added up
+				existing.UniqueBranches, existing.FunctionUniqueBranches = nil, nil
+				// Combined metrics no longer match any one content
+				if existing.SourceHash != result.SourceHash {
+					existing.SourceHash = ""
+				}
+			default:
+				return nil, fmt.Errorf("unknown merge rule %q", rule)
+			}
+		}
+	}
+
+	merged := make([]FileResult, 0, len(byPath))
+	for _, result := range byPath {
+		merged = append(merged, *result)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Path < merged[j].Path
+	})
+	return merged, nil
+}
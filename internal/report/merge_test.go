@@ -0,0 +1,69 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// calls returns function call details of the given callees
+func calls(callees ...string) []metrics.MetricDetail {
+	details := make([]metrics.MetricDetail, 0, len(callees))
+	for _, callee := range callees {
+		details = append(details, metrics.MetricDetail{Text: callee, Context: "Function call"})
+	}
+	return details
+}
+
+// writeReport saves the results as a JSON report without details
+func writeReport(t *testing.T, results []FileResult) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := WriteJSON(f, results, true, JSONOptions{}, nil, nil, metrics.DefaultWeights, metrics.DefaultSeverityConfig); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	return path
+}
+
+func TestReadJSONFilesKeepsUniqueBranches(t *testing.T) {
+	result := FileResult{
+		Path:    "a.go",
+		Metrics: metrics.ABCMetrics{Branches: 3, BranchList: calls("f", "f", "g")},
+		Functions: []metrics.FunctionMetrics{
+			{Name: "main", StartLine: 5, Metrics: metrics.ABCMetrics{Branches: 2, BranchList: calls("f", "f")}},
+		},
+	}
+
+	read, err := ReadJSONFiles(writeReport(t, []FileResult{result}))
+	if err != nil {
+		t.Fatalf("ReadJSONFiles: %v", err)
+	}
+	if len(read) != 1 || len(read[0].Metrics.BranchList) != 0 {
+		t.Fatalf("got %+v, want one file without details", read)
+	}
+
+	again := NewJSONResult(read[0], JSONOptions{}, metrics.DefaultWeights, metrics.DefaultSeverityConfig)
+	if again.UniqueBranches == nil || *again.UniqueBranches != 2 {
+		t.Errorf("file uniqueBranches = %v, want 2", again.UniqueBranches)
+	}
+	if len(again.Functions) != 1 || again.Functions[0].UniqueBranches == nil || *again.Functions[0].UniqueBranches != 1 {
+		t.Errorf("function uniqueBranches = %+v, want 1", again.Functions)
+	}
+
+	// Distinct callees of two reports cannot be combined
+	merged, err := Merge([][]FileResult{read, read}, MergeCombine)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	combined := NewJSONResult(merged[0], JSONOptions{}, metrics.DefaultWeights, metrics.DefaultSeverityConfig)
+	if combined.UniqueBranches != nil {
+		t.Errorf("combined uniqueBranches = %d, want it unknown", *combined.UniqueBranches)
+	}
+}
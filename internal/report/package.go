@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// PackageResult holds the analysis results of the files of a Go package
+type PackageResult struct {
+	Path  string       // Import path of the package
+	Files []FileResult // Per-file results, in path order
+}
+
+// Metrics combines the metrics of every file in the package
+func (p PackageResult) Metrics() metrics.ABCMetrics {
+	all := make([]metrics.ABCMetrics, 0, len(p.Files))
+	for _, file := range p.Files {
+		all = append(all, file.Metrics)
+	}
+	return metrics.CombineMetrics(all...)
+}
+
+// JSONPackage is the JSON representation of a package's combined metrics
+type JSONPackage struct {
+	Package     string       `json:"package"`     // Import path of the package
+	Assignments int          `json:"assignments"` // Number of assignments in all files
+	Branches    int          `json:"branches"`    // Number of branches in all files
+	Conditions  int          `json:"conditions"`  // Number of conditions in all files
+	Score       float64      `json:"score"`       // Combined ABC score rounded to two decimals
+	Severity    string       `json:"severity"`    // Severity level of the combined score
+	Files       []JSONResult `json:"files"`       // Per-file metrics
+}
+
+// WritePackagesJSON writes the package results as an indented JSON array,
+// one object per package with its combined metrics and its files
+func WritePackagesJSON(w io.Writer, packages []PackageResult, opts JSONOptions, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	jsonPackages := make([]JSONPackage, 0, len(packages))
+	for _, pkg := range packages {
+		m := pkg.Metrics()
+		jsonPackage := JSONPackage{
+			Package:     pkg.Path,
+			Assignments: m.Assignments,
+			Branches:    m.Branches,
+			Conditions:  m.Conditions,
+			Score:       RoundScore(m.ScoreWith(weights)),
+			Severity:    metrics.SeverityLevelWith(m.ScoreWith(weights), severity),
+			Files:       []JSONResult{},
+		}
+		for _, file := range pkg.Files {
+			jsonPackage.Files = append(jsonPackage.Files, NewJSONResult(file, opts, weights, severity))
+		}
+		jsonPackages = append(jsonPackages, jsonPackage)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(jsonPackages)
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// RawVersion is the version of the raw results file format
+const RawVersion = 1
+
+// FileResult holds the analysis result of a single file
+type FileResult struct {
+	Path       string                    `json:"path"`                 // Path of the analyzed file
+	Metrics    metrics.ABCMetrics        `json:"metrics"`              // Full metrics including detail lists
+	Functions  []metrics.FunctionMetrics `json:"functions,omitempty"`  // Per-function metrics, only with --by-function
+	SourceHash string                    `json:"sourceHash,omitempty"` // SHA-256 of the analyzed content, when it was hashed
+
+	// Distinct callee counts read back from a report without branch details,
+	// which cannot be recomputed from the metrics. Functions are keyed by
+	// their start line.
+	UniqueBranches         *int        `json:"uniqueBranches,omitempty"`
+	FunctionUniqueBranches map[int]int `json:"functionUniqueBranches,omitempty"`
+}
+
+// Failure records a file that could not be analyzed
+type Failure struct {
+	Path  string `json:"path"`  // Path of the file
+	Error string `json:"error"` // Why the analysis failed
+}
+
+// Total holds the combined metrics of several files
+type Total struct {
+	Files   int                // Number of files combined
+	Metrics metrics.ABCMetrics // Combined metrics of the files
+}
+
+// NewTotal combines the metrics of the results
+func NewTotal(results []FileResult) Total {
+	all := make([]metrics.ABCMetrics, 0, len(results))
+	for _, result := range results {
+		all = append(all, result.Metrics)
+	}
+	return Total{Files: len(results), Metrics: metrics.CombineMetrics(all...)}
+}
+
+// Raw is the on-disk representation of an analysis run. It stores everything
+// needed to render a report later without re-parsing the source files.
+type Raw struct {
+	Version int          `json:"version"` // Raw file format version
+	Files   []FileResult `json:"files"`   // Per-file results
+}
+
+// WriteRaw saves the given results as a raw results file
+func WriteRaw(path string, files []FileResult) error {
+	data, err := json.MarshalIndent(Raw{Version: RawVersion, Files: files}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding raw results: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing raw results: %w", err)
+	}
+	return nil
+}
+
+// ReadRaw loads a raw results file previously written by WriteRaw
+func ReadRaw(path string) (Raw, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Raw{}, fmt.Errorf("error reading raw results: %w", err)
+	}
+
+	var raw Raw
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Raw{}, fmt.Errorf("error decoding raw results: %w", err)
+	}
+
+	if raw.Version != RawVersion {
+		return Raw{}, fmt.Errorf("unsupported raw results version %d (expected %d)", raw.Version, RawVersion)
+	}
+	return raw, nil
+}
@@ -0,0 +1,185 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/abc-metrics/abc/internal/metrics"
+)
+
+// SARIFRuleID is the rule id of every result in SARIF output
+const SARIFRuleID = "abc/high-complexity"
+
+// The types below model the subset of SARIF 2.1.0 written by WriteSARIF
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+		FullDescription  sarifMessage `json:"fullDescription"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+	}
+)
+
+// WriteSARIF writes a SARIF 2.1.0 log with one result per file whose score
+// exceeds the threshold, or per function with byFunction. A threshold of 0
+// uses the start of the High severity level. Each result points at the first
+// counted item of the file or at the start of the function, and its level
+// follows the severity.
+func WriteSARIF(w io.Writer, results []FileResult, byFunction bool, threshold float64, weights metrics.Weights, severity metrics.SeverityConfig) error {
+	if threshold == 0 {
+		threshold = severity.Medium
+	}
+
+	sarifResults := []sarifResult{}
+	for _, result := range results {
+		if !byFunction || len(result.Functions) == 0 {
+			if result.Metrics.ScoreWith(weights) > threshold {
+				sarifResults = append(sarifResults, newSARIFResult(result.Path, "", 0, result.Metrics, threshold, weights, severity))
+			}
+			continue
+		}
+
+		for _, fn := range result.Functions {
+			if fn.Metrics.ScoreWith(weights) > threshold {
+				sarifResults = append(sarifResults, newSARIFResult(result.Path, fn.Name, fn.StartLine, fn.Metrics, threshold, weights, severity))
+			}
+		}
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name: "abc",
+				Rules: []sarifRule{{
+					ID:               SARIFRuleID,
+					ShortDescription: sarifMessage{Text: "ABC complexity score too high"},
+					FullDescription:  sarifMessage{Text: "The ABC score, sqrt(A² + B² + C²) of assignments, branches and conditions, exceeds the configured threshold."},
+				}},
+			}},
+			Results: sarifResults,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(log)
+}
+
+// newSARIFResult builds the result for a file, or a function starting at the
+// given line when name is set
+func newSARIFResult(path, name string, startLine int, m metrics.ABCMetrics, threshold float64, weights metrics.Weights, severity metrics.SeverityConfig) sarifResult {
+	subject := "File"
+	if name != "" {
+		subject = "Function " + name
+	}
+
+	line, col := firstDetail(m)
+	if startLine > 0 {
+		line, col = startLine, 1
+	}
+	return sarifResult{
+		RuleID: SARIFRuleID,
+		Level:  sarifLevel(metrics.SeverityLevelWith(m.ScoreWith(weights), severity)),
+		Message: sarifMessage{Text: fmt.Sprintf("%s has ABC score %.2f (A=%d, B=%d, C=%d), above the threshold of %.2f",
+			subject, m.ScoreWith(weights), m.Assignments, m.Branches, m.Conditions, threshold)},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(path)},
+				Region:           sarifRegion{StartLine: line, StartColumn: col},
+			},
+		}},
+	}
+}
+
+// firstDetail returns the position of the earliest counted item, or the start
+// of the file when nothing was counted
+func firstDetail(m metrics.ABCMetrics) (int, int) {
+	line, col := 0, 0
+	for _, list := range [][]metrics.MetricDetail{m.AssignmentList, m.BranchList, m.ConditionList} {
+		for _, d := range list {
+			if line == 0 || d.Line < line || d.Line == line && d.Col < col {
+				line, col = d.Line, d.Col
+			}
+		}
+	}
+
+	if line == 0 {
+		return 1, 1
+	}
+	return line, col
+}
+
+// sarifLevel maps a severity level to a SARIF result level
+func sarifLevel(severity string) string {
+	switch severity {
+	case "Very High":
+		return "error"
+	case "High":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifURI turns a file path into a SARIF artifact URI. Relative paths stay
+// relative so code scanning resolves them against the repository root.
+func sarifURI(path string) string {
+	if filepath.IsAbs(path) {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+	return (&url.URL{Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,16 @@
+package main
+
+// accumulate uses every kind of assignment statement. Each is recorded with
+// its operator: Definition (:=), Assignment (=) or Compound assignment (+=,
+// <<= and so on). All of them count the same: A=7, B=0, C=1.
+func accumulate(values []int) (int, int) {
+	sum, count := 0, 0         // Definition (:=) of sum + Definition (:=) of count
+	for _, v := range values { // Condition
+		sum += v          // Compound assignment (+=)
+		count = count + 1 // Assignment (=)
+	}
+	mask := 1         // Definition (:=)
+	mask <<= count    // Compound assignment (<<=)
+	sum = sum &^ mask // Assignment (=)
+	return sum, count
+}
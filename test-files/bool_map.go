@@ -0,0 +1,10 @@
+package main
+
+// pick simulates a ternary with a map[bool]T lookup. The composite literal
+// and the index expression are not control flow and the cond identifier is
+// not a logical operator: A=1, B=0, C=0. The literal fields are not counted
+// as assignments.
+func pick(cond bool) string {
+	result := map[bool]string{true: "a", false: "b"}[cond] // Assignment
+	return result
+}
@@ -0,0 +1,18 @@
+//go:build abc_extra
+
+package main
+
+// This file is only part of the build with the abc_extra build tag, so
+// walking test-files skips it unless --tags abc_extra is given. Analyzed
+// directly, it counts as usual: A=2, B=0, C=2.
+
+// extraLimit caps n at the limit of the extra build
+func extraLimit(n int) int {
+	limit := 100   // Assignment (:=)
+	if n > limit { // Condition (if)
+		n = limit // Assignment (=)
+	} else { // Condition (else branch)
+		return n
+	}
+	return n
+}
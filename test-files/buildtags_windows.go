@@ -0,0 +1,16 @@
+package main
+
+// This file is only part of the build on Windows, so walking test-files
+// skips it unless GOOS=windows is set. Analyzed directly, it counts as usual:
+// A=1, B=1, C=1.
+
+import "os"
+
+// configDir returns the directory of the per-user configuration on Windows
+func configDir() string {
+	dir := os.Getenv("APPDATA") // Assignment (:=) + Branch (os.Getenv)
+	if dir == "" {              // Condition (if)
+		return `C:\ProgramData`
+	}
+	return dir
+}
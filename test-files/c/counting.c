@@ -0,0 +1,85 @@
+/*
+ * Annotated C fixture for the C/C++ analyzer. Every counted item carries a
+ * comment naming it. Totals for the whole file: A=15, B=6, C=13.
+ *
+ * Preprocessor directives are skipped, so nothing below counts, not even the
+ * call and the condition in the macro body.
+ */
+#include <stdio.h>
+#include <string.h>
+#define MAX(a, b) ((a) > (b) ? (a) : (b))
+#define LOG(msg) \
+	if (verbose) puts(msg)
+
+#if defined(DEBUG) && DEBUG > 1
+#endif
+
+static int verbose = 0; /* Assignment (verbose) */
+
+struct buffer {
+	char data[64];
+	int len;
+};
+
+/* Declarations are not calls */
+int count_words(const char *text);
+static void reset(struct buffer *buf);
+
+/* Literals and comments are never counted: "if (x) f(y) = 1" 'a' */
+int count_words(const char *text)
+{
+	int words = 0;       // Assignment (words)
+	int in_word = 0;     // Assignment (in_word)
+	const char *quote = "if (a && b) call(x);"; // Assignment (quote)
+	char sep = '?';      // Assignment (sep)
+
+	for (const char *p = text; *p != '\0'; p++) { // Condition (for), Assignment (p), Increment (p)
+		if (*p == ' ' || *p == sep) { // Condition (if), Logical operator (||)
+			in_word = 0; // Assignment (in_word)
+		} else if (!in_word) { // Condition (if)
+			in_word = 1; // Assignment (in_word)
+			words++;     // Increment (words)
+		}
+	}
+	(void)quote;
+	return words;
+}
+
+static void reset(struct buffer *buf)
+{
+	memset(buf->data, 0, sizeof(buf->data)); // Branch (memset)
+	buf->len = 0;                            // Assignment (buf->len)
+}
+
+int main(int argc, char **argv)
+{
+	struct buffer buf;
+	int total = 0; // Assignment (total)
+
+	reset(&buf); // Branch (reset)
+	while (argc > 1 && argv[1] != NULL) { // Condition (while), Logical operator (&&)
+		total += count_words(argv[1]); // Compound assignment (total), Branch (count_words)
+		argc--;                        // Decrement (argc)
+	}
+
+	do { // Condition (do)
+		total--; // Decrement (total)
+	} while (total > 100);
+
+	switch (total) { // Condition (switch)
+	case 0: // Condition (case)
+		puts("none"); // Branch (puts)
+		break;
+	case 1: // Condition (case)
+		puts("one"); // Branch (puts)
+		break;
+	default:
+		printf("%d\n", total > 9 ? 9 : total); // Branch (printf), Condition (ternary)
+	}
+
+	if (total) { // Condition (if)
+		return 1;
+	} else { // Condition (else branch)
+		return 0;
+	}
+}
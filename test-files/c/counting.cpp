@@ -0,0 +1,56 @@
+// Annotated C++ fixture for the C/C++ analyzer. Every counted item carries a
+// comment naming it. Totals for the whole file: A=7, B=6, C=5.
+#include <string>
+#include <vector>
+
+class Shape {
+public:
+	virtual ~Shape() = default;
+	virtual double area() const = 0;
+	Shape &operator=(const Shape &) = delete;
+};
+
+class Counter {
+public:
+	explicit Counter(int start) : count_(start) {}
+
+	void add(int n);
+	int value() const { return count_; }
+
+private:
+	int count_;
+};
+
+void Counter::add(int n)
+{
+	count_ += n; // Compound assignment (count_)
+}
+
+std::vector<int> evens(const std::vector<int> &in)
+{
+	std::vector<int> out;
+	for (auto v : in) { // Condition (for)
+		if (v % 2 == 0) { // Condition (if)
+			out.push_back(v); // Branch (out.push_back)
+		}
+	}
+	return out;
+}
+
+int run()
+{
+	Counter c(1'000);                   // Counted as a declaration, not a call
+	auto raw = R"(if (x) { f(y); })"; // Assignment (raw)
+	int total = 0;                      // Assignment (total)
+
+	c.add(2); // Branch (c.add)
+	for (int v : evens({1, 2, 3, 4})) { // Condition (for), Branch (evens)
+		total += v; // Compound assignment (total)
+	}
+
+	auto twice = [](int x) { return x * 2; }; // Assignment (twice)
+	total = twice(total);                     // Assignment (total), Branch (twice)
+	std::string name = std::to_string(total); // Assignment (name), Branch (std::to_string)
+
+	return total > 10 && !name.empty() ? 1 : 0; // Logical operator (&&), Branch (name.empty), Condition (ternary)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "strings"
+
+// Branches on method calls are named after their receiver expression.
+// Totals for the whole file, including the helpers: A=2, B=11, C=0.
+
+// builder is a chainable string builder
+type builder struct{ parts []string }
+
+// add appends a part and returns the builder for chaining
+func (b *builder) add(s string) *builder { b.parts = append(b.parts, s); return b }
+
+// String joins the parts
+func (b *builder) String() string { return strings.Join(b.parts, " ") }
+
+// newBuilder returns an empty builder
+func newBuilder() *builder { return &builder{} }
+
+// chainedCalls names each call of a chain after its whole receiver, with
+// call arguments elided. Every call of the chain starts where the chain
+// does, so all four are on the first line: newBuilder, newBuilder().add,
+// newBuilder().add(...).add and newBuilder().add(...).add(...).String.
+// A=0, B=4, C=0.
+func chainedCalls() string {
+	return newBuilder(). // 4 Branches (see above)
+				add("a").
+				add("b").
+				String()
+}
+
+// indexedCalls calls methods on indexed receivers. Names and literals are
+// kept as indexes, anything else is elided, including selectors on
+// expressions that are not spelled out: A=1, B=4, C=0.
+func indexedCalls(bs []*builder, byName map[string]*builder, i int) {
+	bs[i].add("x")          // Branch (bs[i].add)
+	byName["main"].add("y") // Branch (byName["main"].add)
+	n := i + 1              // Assignment (n)
+	bs[n-1].add("z")        // Branch (bs[...].add)
+
+	bs[struct{ i int }{i}.i].add("w") // Branch (bs[...].add)
+}
+
+// parenthesizedCall dereferences the receiver explicitly: A=0, B=1, C=0.
+func parenthesizedCall(b **builder) string {
+	return (*b).String() // Branch ((*b).String)
+}
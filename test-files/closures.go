@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// With --by-function, every function literal is a scope of its own, named
+// like the Go runtime names closures, and the enclosing function leaves it
+// out. Without --by-function nothing changes and the file counts as a whole.
+//
+//	sortedNames         A=1, B=2, C=0
+//	sortedNames.func1   A=0, B=4, C=1
+//	sortedNames.func2   A=0, B=1, C=1
+//	sortedNames.func2.1 A=0, B=0, C=2
+//	mapNames            A=2, B=3, C=1
+//	retry               A=1, B=1, C=1
+//	retry.func1         A=2, B=1, C=1
+//
+// The file as a whole: A=6, B=12, C=7.
+
+// sortedNames sorts the names by length, then alphabetically, and upper-cases
+// them
+func sortedNames(names []string) []string {
+	sort.Slice(names, func(i, j int) bool { // Branch (sort.Slice)
+		if len(names[i]) != len(names[j]) { // sortedNames.func1: Condition (if) + Branch (len) x2
+			return len(names[i]) < len(names[j]) // sortedNames.func1: Branch (len) x2
+		}
+		return names[i] < names[j]
+	})
+
+	upper := mapNames(names, func(name string) string { // Assignment (:=) + Branch (mapNames)
+		if name == "" { // sortedNames.func2: Condition (if)
+			return name
+		}
+		return strings.Map(func(r rune) rune { // sortedNames.func2: Branch (strings.Map)
+			if r >= 'a' && r <= 'z' { // sortedNames.func2.1: Condition (if) + Logical operator (&&)
+				return r - 'a' + 'A'
+			}
+			return r
+		}, name)
+	})
+	return upper
+}
+
+// mapNames applies f to every name
+func mapNames(names []string, f func(string) string) []string {
+	out := make([]string, len(names)) // Assignment (:=) + Branch (make) + Branch (len)
+	for i, name := range names {      // Condition (for range)
+		out[i] = f(name) // Assignment (=) + Branch (f)
+	}
+	return out
+}
+
+// retry calls attempt until it succeeds or the tries are used up
+func retry(tries int, attempt func() error) error {
+	var err error
+	try := func() bool { // Assignment (:=)
+		err = attempt()                // retry.func1: Assignment (=) + Branch (attempt)
+		tries--                        // retry.func1: Decrement
+		return err != nil && tries > 0 // retry.func1: Logical operator (&&)
+	}
+	for try() { // Condition (for) + Branch (try)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package main
+
+// Comparisons are not conditions by default. With --count-comparisons (or
+// --spec fitzpatrick) every ==, !=, <, <=, > and >= adds one, listed as
+// (Comparison) in the --show details. The other counts never change:
+//
+//	default:             A=4, B=0, C=7
+//	--count-comparisons: A=4, B=0, C=15
+
+// inRange reports whether lo <= v <= hi
+func inRange(v, lo, hi int) bool {
+	return lo <= v && v <= hi // Logical operator (&&) + Comparison (<=) x2, only with --count-comparisons
+}
+
+// clampScore keeps the score within 0 and 100
+func clampScore(score int) int {
+	if score < 0 { // Condition (if) + Comparison (<), only with --count-comparisons
+		score = 0 // Assignment (=)
+	} else if score > 100 { // Condition (if) + Comparison (>), only with --count-comparisons
+		score = 100 // Assignment (=)
+	}
+	return score
+}
+
+// sameCell compares two cells field by field, outside of any condition
+func sameCell(a, b coord) bool {
+	equal := a.X == b.X && a.Y == b.Y // Assignment (:=) + Logical operator (&&) + Comparison (==) x2, only with --count-comparisons
+	return equal
+}
+
+// classify names the sign of n; the cases of a tagless switch are
+// conditions already, their comparisons add one each
+func classify(n int) string {
+	switch { // Condition (switch)
+	case n < 0: // Condition (case) + Comparison (<), only with --count-comparisons
+		return "negative"
+	case n != 0: // Condition (case) + Comparison (!=), only with --count-comparisons
+		return "positive"
+	}
+	label := "zero" // Assignment (:=)
+	return label
+}
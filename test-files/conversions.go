@@ -0,0 +1,13 @@
+package main
+
+import "strings"
+
+// celsiusToString mixes type conversions with genuine calls. Conversions to
+// predeclared and composite types are not branches: A=3, B=2, C=0.
+func celsiusToString(f float64) []byte {
+	whole := int(f)                 // Assignment (conversion, not a branch)
+	raw := []byte("x")              // Assignment (conversion, not a branch)
+	label := strings.ToUpper("deg") // Assignment + Branch (strings.ToUpper)
+	consume(whole, label)           // Branch (consume)
+	return raw
+}
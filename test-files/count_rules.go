@@ -0,0 +1,26 @@
+package main
+
+// The counting rules of CountConfig that leave out items counted by default.
+// Totals below are given as default / with the flag named in each comment.
+
+// deferredRollback defers a call and a closure. With --skip-defers the
+// deferred calls are left out, but the call inside the closure still counts:
+// A=0, B=3, C=1 / A=0, B=1, C=1.
+func deferredRollback(t *tx, err error) {
+	defer t.Rollback() // Branch (t.Rollback)
+	defer func() {     // Branch (func literal)
+		if err != nil { // Condition
+			t.Rollback() // Branch (t.Rollback)
+		}
+	}()
+}
+
+// assertedLength asserts twice. With --skip-type-assertions neither
+// assertion is a condition, while the if and the call still count:
+// A=2, B=1, C=3 / A=2, B=1, C=1.
+func assertedLength(v any) int {
+	if s, ok := v.(string); ok { // 2 Assignments (s, ok) + Condition (type assertion) + Condition (if)
+		return len(s) // Branch (len)
+	}
+	return v.([]int)[0] // Condition (type assertion)
+}
@@ -0,0 +1,40 @@
+# Counting fixture for the Python analyzer. The analyzer works on tokens, so
+# every annotation below describes the token pattern counted.
+# Expected totals: A=13, B=7, C=14.
+import os.path
+
+
+class Inventory(Base):  # Not counted (class declaration)
+    limit: int = 10  # Assignment (limit)
+
+    def __init__(self, items=None):  # Not counted (declaration and default)
+        super().__init__()  # Branch (super) + Branch (__init__)
+        self.items = items or []  # Assignment (self.items) + Condition (or)
+
+    def add(self, name, count=1):  # Not counted (declaration and default)
+        if not name or count <= 0:  # Condition + Condition (or)
+            raise ValueError("empty")  # Branch (ValueError)
+        elif name in self.items:  # Condition
+            self.items[name] += count  # Assignment (expr)
+        else:  # Condition (else branch)
+            self.items[name] = count  # Assignment (expr)
+
+
+def report(inventory, path):
+    total = count = 0  # Assignment (total) + Assignment (count)
+    first, *rest = sorted(inventory.items)  # Assignment (first, rest) + Branch (sorted)
+    for name in rest:  # Condition
+        count += 1  # Assignment (count)
+    while (n := len(rest)) > 10 and count:  # Condition + Assignment (n) + Branch (len) + Condition (and)
+        rest.pop()  # Branch (rest.pop)
+    try:
+        data = [x for x in rest if x]  # Assignment (data) + Condition (for) + Condition (if)
+    except OSError:  # Condition (except)
+        data = None  # Assignment (data)
+    label = "many" if total > 3 else "few"  # Assignment (label) + Condition (if)
+    match label:  # Condition
+        case "many":  # Condition
+            pass
+        case _:  # Not counted (wildcard case)
+            pass
+    return os.path.join(path, label)  # Branch (os.path.join)
@@ -0,0 +1,39 @@
+// Counting fixture for the TypeScript/JavaScript analyzer. The analyzer works
+// on tokens, so every annotation below describes the token pattern counted.
+// Expected totals: A=8, B=5, C=6.
+
+type Predicate<T> = (item: T) => boolean; // Not counted (type alias)
+
+interface Shape {
+  area(): number; // Not counted (method signature)
+  label?: string; // Not counted (optional marker)
+}
+
+class Box implements Shape {
+  private size: number = 0; // Assignment (size)
+
+  area(): number { // Not counted (method declaration)
+    return this.size * this.size;
+  }
+
+  grow(by: number = 1): void { // Assignment (by)
+    this.size += by; // Assignment (this.size)
+    this.size++; // Assignment (this.size)
+  }
+}
+
+function describe(boxes: Box[], keep: Predicate<Box>): string {
+  let text = ""; // Assignment (text)
+  for (const box of boxes) { // Condition
+    if (keep(box) && box.area() > 0) { // Condition + Condition (&&) + Branch (keep) + Branch (box.area)
+      text += `${box.area()} `; // Assignment (text) + Branch (box.area)
+    }
+  }
+  const pattern = /\d+(\.\d+)?/g; // Assignment (pattern), regex not counted
+  return pattern.test(text) ? text : "none"; // Condition (ternary) + Branch (pattern.test)
+}
+
+const boxes = [new Box()]; // Assignment (boxes) + Branch (Box)
+while (boxes.length < 2 || boxes.length > 3) { // Condition + Condition (||)
+  break;
+}
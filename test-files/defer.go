@@ -0,0 +1,25 @@
+package main
+
+// tx is a minimal transaction
+type tx struct{}
+
+// Rollback undoes the transaction
+func (t *tx) Rollback() error { return nil }
+
+// deferDirect defers a call directly: the deferred call is one branch.
+// A=0, B=1, C=0.
+func deferDirect(t *tx) {
+	defer t.Rollback() // Branch (t.Rollback)
+}
+
+// deferClosure defers a closure. The closure invocation is a branch, and
+// its body is walked like any other code, adding its own condition and
+// branch: A=0, B=2, C=1 for the file. Per function, the closure body is
+// deferClosure.func1 (A=0, B=1, C=1), leaving A=0, B=1, C=0.
+func deferClosure(t *tx, err error) {
+	defer func() { // Branch (func literal)
+		if err != nil { // Condition
+			t.Rollback() // Branch (t.Rollback)
+		}
+	}()
+}
@@ -0,0 +1,7 @@
+# The enclosing directory is named like a Python file on purpose. 'abc analyze
+# test-files/directory.py' walks it like any other directory and reports this
+# file, while analyzing the directory itself as a file, as the library's
+# Analyze does, fails with a DirectoryError instead of a read error.
+# Totals: A=1, B=1, C=1.
+if ready:  # Condition (if)
+    value = compute()  # Assignment (value), Branch (compute)
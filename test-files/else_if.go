@@ -0,0 +1,52 @@
+package main
+
+// gradeChain is a three-level else-if chain without a trailing else.
+// Each if statement in the chain counts once: C=3.
+func gradeChain(score int) string {
+	grade := "F"     // Assignment
+	if score >= 90 { // Condition
+		grade = "A" // Assignment
+	} else if score >= 80 { // Condition
+		grade = "B" // Assignment
+	} else if score >= 70 { // Condition
+		grade = "C" // Assignment
+	}
+	return grade
+}
+
+// gradeChainWithElse is the same chain closed by a trailing else block.
+// The bare else adds a condition of its own: C=4.
+func gradeChainWithElse(score int) string {
+	var grade string
+	if score >= 90 { // Condition
+		grade = "A" // Assignment
+	} else if score >= 80 { // Condition
+		grade = "B" // Assignment
+	} else if score >= 70 { // Condition
+		grade = "C" // Assignment
+	} else { // Condition (else branch)
+		grade = "F" // Assignment
+	}
+	return grade
+}
+
+// sign has an if statement without an else: C=1.
+func sign(n int) int {
+	result := 1 // Assignment
+	if n < 0 {  // Condition
+		result = -1 // Assignment
+	}
+	return result
+}
+
+// signWithElse is sign with the assignment moved into an else branch, which
+// counts as a second condition: C=2.
+func signWithElse(n int) int {
+	var result int
+	if n < 0 { // Condition
+		result = -1 // Assignment
+	} else { // Condition (else branch)
+		result = 1 // Assignment
+	}
+	return result
+}
@@ -0,0 +1,20 @@
+package main
+
+// bonusPoints chains three cases with fallthrough. Each case clause is
+// counted exactly once: 4 conditions in total (the switch plus three cases).
+func bonusPoints(score int) int {
+	bonus := 0 // Assignment
+
+	switch { // Condition
+	case score >= 90: // Condition
+		bonus += 3 // Assignment
+		fallthrough
+	case score >= 80: // Condition
+		bonus += 2 // Assignment
+		fallthrough
+	case score >= 70: // Condition
+		bonus += 1 // Assignment
+	}
+
+	return bonus
+}
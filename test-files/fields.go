@@ -0,0 +1,32 @@
+package main
+
+import "time"
+
+// serverConfig holds nested configuration sections
+type serverConfig struct {
+	Server struct {
+		Timeout time.Duration
+		TLS     struct {
+			Enabled bool
+		}
+	}
+	Name string
+}
+
+// applyDefaults mutates nested fields. Each assignment is named by its full
+// dotted path in the details: A=5, B=0, C=0.
+func applyDefaults(config *serverConfig) {
+	config.Name = "default"             // Assignment (config.Name)
+	config.Server.Timeout = 30          // Assignment (config.Server.Timeout)
+	config.Server.TLS.Enabled = true    // Assignment (config.Server.TLS.Enabled)
+	timeout := config.Server.Timeout    // Assignment (timeout)
+	config.Server.Timeout = timeout * 2 // Assignment (config.Server.Timeout)
+}
+
+// renameAll assigns through indexed receivers. Targets are named like the
+// receivers of branches, with indexes other than names and literals elided:
+// A=2, B=0, C=0.
+func renameAll(configs map[string]*serverConfig, names []string, i int) {
+	configs["main"].Name = "main"         // Assignment (configs["main"].Name)
+	configs[names[i]].Server.Timeout = 10 // Assignment (configs[...].Server.Timeout)
+}
@@ -0,0 +1,31 @@
+package main
+
+import "sync"
+
+// fireAndForget starts a goroutine that nothing waits for.
+// With --lint-goroutines this reports a warning on the go statement.
+func fireAndForget(work func()) {
+	go work()
+}
+
+// waitForAll joins its goroutines with a WaitGroup: no warning.
+func waitForAll(jobs []func()) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job func()) {
+			defer wg.Done()
+			job()
+		}(job)
+	}
+	wg.Wait()
+}
+
+// collect joins its goroutine with a channel receive: no warning.
+func collect(compute func() int) int {
+	results := make(chan int)
+	go func() {
+		results <- compute()
+	}()
+	return <-results
+}
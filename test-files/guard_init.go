@@ -0,0 +1,12 @@
+package main
+
+// lookup returns a value or an error
+func lookup() (*int, error) { return nil, nil }
+
+// guardWithInit combines an init assignment, a call, the if condition and a
+// logical operator in a single guard: A=2, B=1, C=2.
+func guardWithInit() {
+	if v, err := lookup(); err != nil && v == nil { // 2 Assignments (v, err) + Branch (lookup) + 2 Conditions (if, &&)
+		return
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import "errors"
+
+// validate opens with guard clauses. With --count-guards the two
+// early-return ifs are tallied as guards; C stays 3 either way.
+func validate(name string, age int) error {
+	if name == "" { // Condition (guard)
+		return errors.New("name is required") // Branch (errors.New)
+	}
+	if age < 0 { // Condition (guard)
+		return errors.New("age must not be negative") // Branch (errors.New)
+	}
+	if age > 150 { // Condition (not a guard: the body does more than return)
+		consume(age)                            // Branch (consume)
+		return errors.New("age is implausible") // Branch (errors.New)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package main
+
+// apply invokes a function-typed parameter. Parameter declarations are not
+// assignments, and calling the callback is one branch named after the
+// parameter: A=0, B=1, C=0.
+func apply(f func(int) int, x int) int {
+	return f(x) // Branch (f)
+}
+
+// applyIfSet guards the callback invocation: A=0, B=1, C=1.
+func applyIfSet(f func(int) int, x int) int {
+	if f != nil { // Condition
+		return f(x) // Branch (f)
+	}
+	return x
+}
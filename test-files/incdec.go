@@ -0,0 +1,22 @@
+package main
+
+// counter tracks hits per key
+type counter struct {
+	total int
+	hits  map[string]int
+}
+
+// tally increments and decrements identifiers, fields and index
+// expressions. Each ++ or -- is one assignment: A=7, B=2, C=2.
+func tally(c *counter, keys []string, counts []int) {
+	for i := 0; i < len(keys); i++ { // Assignment (i) + Condition + Branch (len) + Assignment (i++)
+		c.total++         // Assignment (c.total)
+		c.hits[keys[i]]++ // Assignment (c.hits[...])
+		counts[i]--       // Assignment (counts[i])
+	}
+
+	remaining := len(counts) // Assignment + Branch (len)
+	if remaining > 0 {       // Condition
+		remaining-- // Assignment
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+// Assignments in the init statements of if, switch and for statements, and
+// in the post statements of for loops, are counted by default. With
+// --skip-init-assignments they are left out, while calls and conditions in
+// the same statements still count. Totals below are given as default /
+// --skip-init-assignments.
+
+// ifInit binds two variables in the if init statement:
+// A=2, B=0, C=2 / A=0, B=0, C=2.
+func ifInit(m map[string]int, k string) int {
+	if v, ok := m[k]; ok && v > 0 { // 2 Assignments (v, ok) + 2 Conditions (if, &&)
+		return v
+	}
+	return 0
+}
+
+// switchInit binds a variable in the switch init statement; the call on the
+// right-hand side is still a branch: A=1, B=1, C=2 / A=0, B=1, C=2.
+func switchInit(s []int) string {
+	switch n := len(s); { // Assignment (n) + Branch (len) + Condition (switch)
+	case n > 1: // Condition (case)
+		return "many"
+	}
+	return "few"
+}
+
+// typeSwitchInit has an init statement before the type switch guard, which
+// is not an init statement and always counts:
+// A=2, B=0, C=2 / A=1, B=0, C=2.
+func typeSwitchInit(values []any) bool {
+	switch v := values[0]; x := v.(type) { // Assignment (v) + Assignment (x) + Condition (type switch)
+	case int: // Condition (type case)
+		return x > 0
+	}
+	return false
+}
+
+// forInitPost has an init and a post statement, plus assignments before the
+// loop and in its body that count either way:
+// A=4, B=0, C=1 / A=2, B=0, C=1.
+func forInitPost(n int) int {
+	total := 0               // Assignment (total)
+	for i := 0; i < n; i++ { // Assignment (i) + Increment (i) + Condition (for)
+		total += i // Compound assignment (total)
+	}
+	return total
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// point has a value receiver method
+type point struct {
+	X, Y int
+}
+
+// Sum adds the coordinates
+func (p point) Sum() int {
+	return p.X + p.Y
+}
+
+// freshReceivers calls methods on freshly constructed values. Each call is
+// named after the literal's type: A=0, B=3, C=0. The literal fields are not
+// counted as assignments.
+func freshReceivers() int {
+	(&bytes.Buffer{}).WriteString("x")    // Branch ((bytes.Buffer).WriteString)
+	(&strings.Builder{}).WriteString("y") // Branch ((strings.Builder).WriteString)
+	return point{X: 1, Y: 2}.Sum()        // Branch ((point).Sum)
+}
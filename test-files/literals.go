@@ -0,0 +1,42 @@
+package main
+
+// coord is a small struct for the literal examples
+type coord struct {
+	X, Y int
+}
+
+// mapLiteral initializes a map with four key/value pairs. With
+// --literal-elements each pair also counts as an assignment:
+// A=5, B=0, C=0 with the flag and A=1, B=0, C=0 without it.
+func mapLiteral() map[string]bool {
+	options := map[string]bool{ // Assignment (options)
+		"uppercase": false, // Assignment ("uppercase")
+		"lowercase": false, // Assignment ("lowercase")
+		"trim":      true,  // Assignment ("trim")
+		"prefix":    true,  // Assignment ("prefix")
+	}
+	return options
+}
+
+// structLiteral initializes a struct by field name. With --literal-elements
+// each field counts as an assignment:
+// A=3, B=0, C=0 with the flag and A=1, B=0, C=0 without it.
+func structLiteral() coord {
+	p := coord{ // Assignment (p)
+		X: 1, // Assignment (X)
+		Y: 2, // Assignment (Y)
+	}
+	return p
+}
+
+// sliceLiteral initializes a slice of structs by position. With
+// --literal-elements every element counts, including the fields of the
+// nested literals:
+// A=7, B=0, C=0 with the flag and A=1, B=0, C=0 without it.
+func sliceLiteral() []coord {
+	points := []coord{ // Assignment (points)
+		{1, 2}, // Assignment ([0]) + 2 Assignments ([0], [1])
+		{3, 4}, // Assignment ([1]) + 2 Assignments ([0], [1])
+	}
+	return points
+}
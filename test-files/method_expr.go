@@ -0,0 +1,19 @@
+package main
+
+import "time"
+
+// celsius is a temperature with a value receiver method
+type celsius float64
+
+// String formats the temperature
+func (c celsius) String() string {
+	return time.Duration(c).String() // 2 Branches (time.Duration, time.Duration(...).String)
+}
+
+// formatReadings calls methods through method expressions. Each call is one
+// branch named by its full selector chain: A=2, B=3, C=0.
+func formatReadings(t time.Time, c celsius) string {
+	stamp := time.Time.Format(t, time.RFC3339)            // Assignment + Branch (time.Time.Format)
+	reading := celsius.String(c)                          // Assignment + Branch (celsius.String)
+	return stamp + " " + reading + t.Format(time.Kitchen) // Branch (t.Format)
+}
@@ -0,0 +1,52 @@
+package main
+
+// With --nesting, every condition records its depth: 1 outside any control
+// statement body, plus one for each enclosing if or else block, loop body
+// and case or select clause. The flag never changes the counts.
+
+// nestedLoops walks a grid with an if inside two loops: C=4, max depth 3.
+func nestedLoops(grid [][]int) int {
+	found := 0
+	for _, row := range grid { // Condition (for range), depth 1
+		for _, cell := range row { // Condition (for range), depth 2
+			if cell > 0 && cell < 10 { // Condition (if), depth 3 + Logical operator (&&), depth 3
+				found++
+			}
+		}
+	}
+	return found
+}
+
+// elseIfChain stays at the depth of the chain for every if, while the bare
+// else block is a level of its own for what it contains: C=6, max depth 3.
+func elseIfChain(n int, ok bool) string {
+	if n < 0 { // Condition (if), depth 1
+		return "negative"
+	} else if n == 0 { // Condition (if), depth 1
+		return "zero"
+	} else { // Condition (else branch), depth 1
+		for i := 0; i < n; i++ { // Condition (for), depth 2
+			if ok || i > 2 { // Condition (if), depth 3 + Logical operator (||), depth 3
+				return "big"
+			}
+		}
+	}
+	return "small"
+}
+
+// switchInLoop nests a switch in a loop; its cases are at the depth of the
+// switch and their statements one deeper: C=5, max depth 3.
+func switchInLoop(words []string) int {
+	total := 0
+	for _, w := range words { // Condition (for range), depth 1
+		switch len(w) { // Condition (switch), depth 2
+		case 0: // Condition (case), depth 2
+			continue
+		case 1: // Condition (case), depth 2
+			if w == "a" { // Condition (if), depth 3
+				total++
+			}
+		}
+	}
+	return total
+}
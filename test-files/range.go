@@ -0,0 +1,37 @@
+package main
+
+// rangeForms covers every range binding form. Each loop is one condition
+// and each consume call one branch. With --range-assignments the bound
+// variables also count as assignments, blank or not:
+// A=5, B=3, C=4 with the flag, A=4, B=3, C=4 when --skip-blank is added and
+// A=0, B=3, C=4 without it.
+func rangeForms(xs []string) {
+	for i := range xs { // Condition + Assignment (i)
+		consume(i) // Branch (consume)
+	}
+
+	for i, x := range xs { // Condition + 2 Assignments (i, x)
+		consume(i, x) // Branch (consume)
+	}
+
+	for _, x := range xs { // Condition + 2 Assignments (_, x), 1 with --skip-blank (x)
+		consume(x) // Branch (consume)
+	}
+
+	for range xs { // Condition
+	}
+}
+
+// lastPair assigns to pre-declared loop variables with =. With
+// --range-assignments both targets count as assignments:
+// A=2, B=0, C=1 with the flag and A=0, B=0, C=1 without it.
+func lastPair(xs []string) (int, string) {
+	var i int
+	var x string
+	for i, x = range xs { // Condition + 2 Assignments (i, x)
+	}
+	return i, x
+}
+
+// consume accepts any values so loop variables are used
+func consume(...any) {}
@@ -0,0 +1,28 @@
+package main
+
+// clampOnce has a single exit point. With --count-returns: A=3, B=1, C=2.
+func clampOnce(n, lo, hi int) int {
+	result := n // Assignment
+	if n < lo { // Condition
+		result = lo // Assignment
+	} else if n > hi { // Condition
+		result = hi // Assignment
+	}
+	return result // Branch (return, only with --count-returns)
+}
+
+// clampEarly returns from every branch. With --count-returns: A=0, B=3, C=2.
+func clampEarly(n, lo, hi int) int {
+	if n < lo { // Condition
+		return lo // Branch (return, only with --count-returns)
+	}
+	if n > hi { // Condition
+		return hi // Branch (return, only with --count-returns)
+	}
+	return n // Branch (return, only with --count-returns)
+}
+
+// record has no return statement at all: A=1, B=0, C=0.
+func record(n int) {
+	_ = n // Assignment
+}
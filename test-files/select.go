@@ -0,0 +1,29 @@
+package main
+
+// drain receives from a channel inside a select. Receiving into variables
+// in a select case is an assignment of each target:
+// A=4, B=1, C=2.
+func drain(ch <-chan int, done <-chan struct{}) int {
+	total := 0 // Assignment
+	select {   // Condition
+	case v, ok := <-ch: // 2 Assignments (v, ok)
+		if ok { // Condition
+			total = v // Assignment
+		}
+	case <-done:
+		consume(total) // Branch (consume)
+	}
+	return total
+}
+
+// closed reports whether the channel is closed, without blocking. The blank
+// target counts like ok unless --skip-blank is set:
+// A=2, B=0, C=1, or A=1 with the flag.
+func closed(ch <-chan int) bool {
+	select { // Condition
+	case _, ok := <-ch: // 2 Assignments (_, ok), 1 with --skip-blank (ok)
+		return !ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import "os"
+
+// The same function counted under each --spec:
+//
+//	default:     A=4, B=2, C=5
+//	fitzpatrick: A=6, B=4, C=7 (comparisons, range variables and returns)
+//	minimal:     A=2, B=1, C=4 (no init assignments, deferred calls or
+//	             type assertions)
+
+// sumSmall adds up the values below the limit until the first negative one
+func sumSmall(values []any, limit int, f *os.File) int {
+	defer f.Close() // Branch (f.Close), not with minimal
+
+	sum := 0                       // Assignment (:=)
+	for _, value := range values { // Condition (for range) + 2 Assignments (range key and value), only with fitzpatrick
+		if n, ok := value.(int); ok && n < limit { // Condition (if) + Assignment (n, ok: 2), not with minimal + Condition (type assertion), not with minimal + Logical operator (&&) + Comparison (<), only with fitzpatrick
+			sum += n // Assignment (+=)
+		} else if n < 0 { // Condition (if) + Comparison (<), only with fitzpatrick
+			return sum // Branch (return), only with fitzpatrick
+		}
+	}
+	consume(sum) // Branch (consume)
+	return sum   // Branch (return), only with fitzpatrick
+}
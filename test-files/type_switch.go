@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+// describe is a type switch with two type cases and a default. The switch
+// and each non-default type case count once: A=1, B=1, C=3.
+func describe(v any) string {
+	switch x := v.(type) { // Condition (type switch), Assignment (x)
+	case int: // Condition (type case)
+		return "int"
+	case string, fmt.Stringer: // Condition (type case)
+		return "text"
+	default:
+		return fmt.Sprint(x) // Branch
+	}
+}
+
+// describeShort is describe without the bound variable, so the type switch
+// guard assigns nothing: A=0, B=0, C=2.
+func describeShort(v any) bool {
+	switch v.(type) { // Condition (type switch)
+	case nil: // Condition (type case)
+		return false
+	}
+	return true
+}
+
+// asString uses a comma-ok type assertion, a type check of its own: A=2, B=0, C=2.
+func asString(v any) string {
+	s, ok := v.(string) // Assignment (s), Assignment (ok), Condition (type assertion)
+	if !ok {            // Condition
+		return ""
+	}
+	return s
+}
+
+// mustInt uses a plain type assertion, which panics when the check fails:
+// A=0, B=0, C=1.
+func mustInt(v any) int {
+	return v.(int) // Condition (type assertion)
+}